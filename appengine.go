@@ -3,7 +3,6 @@
 package gforms
 
 import (
-	"fmt"
 	"html/template"
 	"net/url"
 
@@ -46,6 +45,10 @@ func IsBlobstoreFormValid(form Form, blobs map[string][]*blobstore.BlobInfo, for
 
 //------------------------------------------------------------------------------
 
+// BlobField accepts files uploaded to App Engine blobstore.
+//
+// Deprecated: blobstore is not available in modern App Engine runtimes;
+// use StoredFileField with a Storage.
 type BlobField struct {
 	*BaseField
 }
@@ -60,7 +63,7 @@ func (f *BlobField) Value() *blobstore.BlobInfo {
 func (f *BlobField) Validate(rawValue interface{}) error {
 	value, ok := rawValue.(*blobstore.BlobInfo)
 	if !ok {
-		return fmt.Errorf("Type %T is not supported.", rawValue)
+		return errUnsupportedType(rawValue)
 	}
 
 	if err := f.ApplyValidators(value); err != nil {
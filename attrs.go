@@ -6,19 +6,19 @@ import (
 	tTemplate "text/template"
 )
 
+// WidgetAttrs holds HTML attributes of the widget. Values are stored as
+// is and escaped by String.
 type WidgetAttrs struct {
 	attrs [][2]string
 }
 
 func (w *WidgetAttrs) Clone() *WidgetAttrs {
 	return &WidgetAttrs{
-		attrs: w.attrs[:],
+		attrs: append([][2]string(nil), w.attrs...),
 	}
 }
 
 func (w *WidgetAttrs) Set(name, value string) {
-	value = tTemplate.HTMLEscapeString(value)
-
 	exists := false
 	for i := range w.attrs {
 		attr := &w.attrs[i]
@@ -59,10 +59,15 @@ func (w *WidgetAttrs) Names() []string {
 	return names
 }
 
+// String renders attributes with escaped values. Attributes with names
+// that are not valid HTML attribute names are skipped.
 func (w *WidgetAttrs) String() string {
 	attrsArr := make([]string, 0, len(w.attrs))
 	for _, attr := range w.attrs {
-		attrsArr = append(attrsArr, fmt.Sprintf(`%v="%v"`, attr[0], attr[1]))
+		if !isValidAttrName(attr[0]) {
+			continue
+		}
+		attrsArr = append(attrsArr, fmt.Sprintf(`%v="%v"`, attr[0], tTemplate.HTMLEscapeString(attr[1])))
 	}
 	if len(attrsArr) > 0 {
 		return " " + strings.Join(attrsArr, " ")
@@ -75,3 +80,18 @@ func (w *WidgetAttrs) FromSlice(attrs []string) {
 		w.Set(attrs[i], attrs[i+1])
 	}
 }
+
+func isValidAttrName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == ':', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
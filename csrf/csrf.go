@@ -0,0 +1,433 @@
+// Package csrf implements stateless CSRF protection for net/http.
+//
+// Protector keeps random secret in a cookie (double-submit cookie) and
+// issues tokens that are bound to the secret with HMAC. Token carries its
+// issue time and optional scope (e.g. form name), so it expires and can't
+// be used with another form. Unsafe requests are also checked for
+// matching Origin or Referer header.
+//
+// Usage:
+//
+//	p := csrf.New(key)
+//	http.ListenAndServe(":8080", p.Handler(mux))
+//
+//	// in the handler
+//	form.CSRF = p.NewField(r, "article")
+//	gforms.InitForm(form)
+package csrf
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/gforms"
+)
+
+const (
+	DefaultName       = "_csrf"
+	DefaultHeaderName = "X-CSRF-Token"
+	DefaultMaxAge     = 12 * time.Hour
+
+	secretLen = 32
+)
+
+// Error codes of the Field validation errors.
+const (
+	CodeNoCookie     = "csrf_no_cookie"
+	CodeTokenInvalid = "csrf_token_invalid"
+	CodeTokenExpired = "csrf_token_expired"
+)
+
+var (
+	ErrNoCookie      = errors.New("CSRF cookie is missing")
+	ErrTokenMissing  = errors.New("CSRF token is missing")
+	ErrTokenInvalid  = errors.New("CSRF token is invalid")
+	ErrTokenExpired  = errors.New("CSRF token has expired")
+	ErrOriginInvalid = errors.New("Origin or Referer does not match")
+)
+
+type contextKey int
+
+const (
+	secretKey contextKey = iota
+	reasonKey
+)
+
+// Protector issues and verifies CSRF tokens. Zero values of the optional
+// fields are replaced with defaults.
+type Protector struct {
+	// Keys sign tokens. The first key is used to sign; all keys are
+	// accepted, so keys are rotated by prepending new key.
+	Keys [][]byte
+	// MaxAge is token lifetime. Default is DefaultMaxAge.
+	MaxAge time.Duration
+
+	// CookieName and FieldName default to DefaultName, HeaderName
+	// defaults to DefaultHeaderName.
+	CookieName string
+	FieldName  string
+	HeaderName string
+	// CookiePath defaults to "/".
+	CookiePath string
+
+	// TrustedOrigins lists origins (e.g. "https://example.com") that
+	// are accepted besides the origin of the request itself.
+	TrustedOrigins []string
+
+	// ErrorHandler is called when request is rejected. Reason is
+	// returned by FailureReason. Default responds with 403.
+	ErrorHandler http.Handler
+
+	now func() time.Time
+}
+
+// New returns Protector that signs tokens with keys. It panics when no
+// keys are given.
+func New(keys ...[]byte) *Protector {
+	if len(keys) == 0 {
+		panic("csrf: at least one key is required")
+	}
+	return &Protector{
+		Keys: keys,
+	}
+}
+
+// Handler returns middleware that sets secret cookie and rejects unsafe
+// requests (POST, PUT, etc.) without valid token in the header or form
+// field or with foreign Origin/Referer. Token of any scope is accepted;
+// scope is checked by Field. It panics when Protector has no keys.
+func (p *Protector) Handler(h http.Handler) http.Handler {
+	p.mustHaveKeys()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, hasCookie := p.cookieSecret(r)
+		if !hasCookie {
+			secret = newSecret()
+			http.SetCookie(w, &http.Cookie{
+				Name:     p.cookieName(),
+				Value:    base64.RawURLEncoding.EncodeToString(secret),
+				Path:     p.cookiePath(),
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		r = r.WithContext(context.WithValue(r.Context(), secretKey, secret))
+
+		if !isSafeMethod(r.Method) {
+			var err error
+			if err = p.checkOrigin(r); err == nil {
+				if !hasCookie {
+					err = ErrNoCookie
+				} else {
+					err = p.verify(secret, p.requestToken(r), "", false)
+				}
+			}
+			if err != nil {
+				p.fail(w, r, err)
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Token returns new token for given scope. Request should be passed
+// through Handler first; empty string is returned otherwise.
+func (p *Protector) Token(r *http.Request, scope string) string {
+	p.mustHaveKeys()
+	secret, ok := p.secret(r)
+	if !ok {
+		return ""
+	}
+	payload := make([]byte, 8, 8+len(scope))
+	binary.BigEndian.PutUint64(payload, uint64(p.timeNow().Unix()))
+	payload = append(payload, scope...)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(p.mac(p.Keys[0], secret, payload))
+}
+
+// Verify checks that token was issued for the request secret and scope
+// and has not expired.
+func (p *Protector) Verify(r *http.Request, token, scope string) error {
+	secret, ok := p.secret(r)
+	if !ok {
+		return ErrNoCookie
+	}
+	return p.verify(secret, token, scope, true)
+}
+
+func (p *Protector) verify(secret []byte, token, scope string, checkScope bool) error {
+	if token == "" {
+		return ErrTokenMissing
+	}
+	encPayload, encMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil || len(payload) < 8 {
+		return ErrTokenInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil {
+		return ErrTokenInvalid
+	}
+
+	valid := false
+	for _, key := range p.Keys {
+		if hmac.Equal(mac, p.mac(key, secret, payload)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrTokenInvalid
+	}
+	if checkScope && !hmac.Equal(payload[8:], []byte(scope)) {
+		return ErrTokenInvalid
+	}
+
+	issued := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	if p.timeNow().Sub(issued) > p.maxAge() {
+		return ErrTokenExpired
+	}
+	return nil
+}
+
+func (p *Protector) mustHaveKeys() {
+	if len(p.Keys) == 0 {
+		panic("csrf: Protector has no keys")
+	}
+}
+
+func (p *Protector) mac(key, secret, payload []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// checkOrigin compares Origin (or Referer when Origin is not sent) with
+// the request origin and trusted origins. Referer is required for HTTPS
+// requests without Origin.
+func (p *Protector) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Header.Get("Referer")
+		if referer == "" {
+			if r.TLS != nil {
+				return ErrOriginInvalid
+			}
+			return nil
+		}
+		u, err := url.Parse(referer)
+		if err != nil {
+			return ErrOriginInvalid
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if strings.EqualFold(origin, scheme+"://"+r.Host) {
+		return nil
+	}
+	for _, trusted := range p.TrustedOrigins {
+		if strings.EqualFold(origin, trusted) {
+			return nil
+		}
+	}
+	return ErrOriginInvalid
+}
+
+func (p *Protector) requestToken(r *http.Request) string {
+	if token := r.Header.Get(p.headerName()); token != "" {
+		return token
+	}
+	return r.PostFormValue(p.fieldName())
+}
+
+func (p *Protector) fail(w http.ResponseWriter, r *http.Request, err error) {
+	r = r.WithContext(context.WithValue(r.Context(), reasonKey, err))
+	if p.ErrorHandler != nil {
+		p.ErrorHandler.ServeHTTP(w, r)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s - %s", http.StatusText(http.StatusForbidden), err), http.StatusForbidden)
+}
+
+// FailureReason returns error why request was rejected. It should be
+// called from Protector.ErrorHandler.
+func FailureReason(r *http.Request) error {
+	err, _ := r.Context().Value(reasonKey).(error)
+	return err
+}
+
+func (p *Protector) secret(r *http.Request) ([]byte, bool) {
+	if secret, ok := r.Context().Value(secretKey).([]byte); ok {
+		return secret, true
+	}
+	return p.cookieSecret(r)
+}
+
+func (p *Protector) cookieSecret(r *http.Request) ([]byte, bool) {
+	cookie, err := r.Cookie(p.cookieName())
+	if err != nil {
+		return nil, false
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(secret) != secretLen {
+		return nil, false
+	}
+	return secret, true
+}
+
+func (p *Protector) cookieName() string {
+	if p.CookieName != "" {
+		return p.CookieName
+	}
+	return DefaultName
+}
+
+func (p *Protector) cookiePath() string {
+	if p.CookiePath != "" {
+		return p.CookiePath
+	}
+	return "/"
+}
+
+func (p *Protector) fieldName() string {
+	if p.FieldName != "" {
+		return p.FieldName
+	}
+	return DefaultName
+}
+
+func (p *Protector) headerName() string {
+	if p.HeaderName != "" {
+		return p.HeaderName
+	}
+	return DefaultHeaderName
+}
+
+func (p *Protector) maxAge() time.Duration {
+	if p.MaxAge != 0 {
+		return p.MaxAge
+	}
+	return DefaultMaxAge
+}
+
+func (p *Protector) timeNow() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+	return false
+}
+
+func newSecret() []byte {
+	b := make([]byte, secretLen)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+//------------------------------------------------------------------------------
+
+// Field is hidden form field with CSRF token for given scope. It is
+// valid when submitted token was issued for the same request secret and
+// scope and has not expired.
+type Field struct {
+	*gforms.BaseField
+	p     *Protector
+	r     *http.Request
+	scope string
+	token string
+}
+
+func (p *Protector) NewField(r *http.Request, scope string) *Field {
+	f := &Field{
+		BaseField: &gforms.BaseField{},
+		p:         p,
+		r:         r,
+		scope:     scope,
+		token:     p.Token(r, scope),
+	}
+	f.SetWidget(gforms.NewHiddenWidget())
+	f.SetName(p.fieldName())
+	f.SetIsRequired(true)
+	f.SetLabel("")
+	return f
+}
+
+// Value returns token that is rendered by the field.
+func (f *Field) Value() string {
+	return f.token
+}
+
+// Validate returns *gforms.ValidationError that wraps the error returned
+// by Verify, so both errors.As and errors.Is can be used.
+func (f *Field) Validate(rawValue interface{}) error {
+	value, ok := rawValue.(string)
+	if !ok {
+		return gforms.NewValidationError(
+			gforms.CodeUnsupportedType,
+			"Type {type} is not supported",
+			map[string]interface{}{"type": fmt.Sprintf("%T", rawValue)},
+		)
+	}
+	err := f.p.Verify(f.r, value, f.scope)
+	if err == nil {
+		return nil
+	}
+	ferr := &fieldError{err: err}
+	switch err {
+	case ErrNoCookie:
+		ferr.verr = gforms.NewValidationError(CodeNoCookie, "Enable cookies to submit this form", nil)
+	case ErrTokenExpired:
+		ferr.verr = gforms.NewValidationError(CodeTokenExpired, "This form has expired, reload the page and try again", nil)
+	default:
+		ferr.verr = gforms.NewValidationError(CodeTokenInvalid, "This form is invalid, reload the page and try again", nil)
+	}
+	return ferr
+}
+
+// fieldError is validation error of Field that wraps Verify error.
+type fieldError struct {
+	verr *gforms.ValidationError
+	err  error
+}
+
+func (e *fieldError) Error() string {
+	return e.verr.Error()
+}
+
+func (e *fieldError) Unwrap() []error {
+	return []error{e.verr, e.err}
+}
+
+func (f *Field) Render(attrs ...string) template.HTML {
+	return f.Widget().Render(attrs, f.token)
+}
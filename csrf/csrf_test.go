@@ -0,0 +1,173 @@
+package csrf
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type CSRFTest struct {
+	p     *Protector
+	token string
+}
+
+var _ = Suite(&CSRFTest{})
+
+func (t *CSRFTest) SetUpTest(c *C) {
+	t.p = New([]byte("secret"))
+	t.token = ""
+}
+
+// do passes request through the middleware and stores token with "form"
+// scope issued by the handler.
+func (t *CSRFTest) do(r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	t.p.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.token = t.p.NewField(r, "form").Value()
+	})).ServeHTTP(w, r)
+	return w
+}
+
+func (t *CSRFTest) get(c *C) *http.Cookie {
+	w := t.do(httptest.NewRequest("GET", "http://example.com/", nil))
+	c.Assert(w.Code, Equals, http.StatusOK)
+	cookies := w.Result().Cookies()
+	c.Assert(cookies, HasLen, 1)
+	c.Assert(cookies[0].Name, Equals, "_csrf")
+	c.Assert(cookies[0].HttpOnly, Equals, true)
+	c.Assert(t.token, Not(Equals), "")
+	return cookies[0]
+}
+
+func post(cookie *http.Cookie, values url.Values) *http.Request {
+	r := httptest.NewRequest("POST", "http://example.com/", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func (t *CSRFTest) TestMiddleware(c *C) {
+	cookie := t.get(c)
+	token := t.token
+
+	w := t.do(post(cookie, url.Values{"_csrf": {token}}))
+	c.Assert(w.Code, Equals, http.StatusOK)
+
+	r := post(cookie, nil)
+	r.Header.Set("X-CSRF-Token", token)
+	c.Assert(t.do(r).Code, Equals, http.StatusOK)
+
+	c.Assert(t.do(post(cookie, nil)).Code, Equals, http.StatusForbidden)
+	c.Assert(t.do(post(nil, url.Values{"_csrf": {token}})).Code, Equals, http.StatusForbidden)
+	c.Assert(t.do(post(cookie, url.Values{"_csrf": {token + "x"}})).Code, Equals, http.StatusForbidden)
+
+	other := t.get(c)
+	c.Assert(t.do(post(other, url.Values{"_csrf": {token}})).Code, Equals, http.StatusForbidden)
+}
+
+func (t *CSRFTest) TestOrigin(c *C) {
+	cookie := t.get(c)
+	values := url.Values{"_csrf": {t.token}}
+
+	r := post(cookie, values)
+	r.Header.Set("Origin", "http://evil.com")
+	c.Assert(t.do(r).Code, Equals, http.StatusForbidden)
+
+	r = post(cookie, values)
+	r.Header.Set("Referer", "http://evil.com/page")
+	c.Assert(t.do(r).Code, Equals, http.StatusForbidden)
+
+	r = post(cookie, values)
+	r.Header.Set("Referer", "http://example.com/page")
+	c.Assert(t.do(r).Code, Equals, http.StatusOK)
+
+	t.p.TrustedOrigins = []string{"https://app.example.com"}
+	r = post(cookie, values)
+	r.Header.Set("Origin", "https://app.example.com")
+	c.Assert(t.do(r).Code, Equals, http.StatusOK)
+}
+
+func (t *CSRFTest) TestErrorHandler(c *C) {
+	var reason error
+	t.p.ErrorHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reason = FailureReason(r)
+		w.WriteHeader(http.StatusTeapot)
+	})
+	cookie := t.get(c)
+
+	c.Assert(t.do(post(cookie, nil)).Code, Equals, http.StatusTeapot)
+	c.Assert(reason, Equals, ErrTokenMissing)
+}
+
+func (t *CSRFTest) TestFieldScopeAndExpiry(c *C) {
+	cookie := t.get(c)
+	token := t.token
+
+	r := post(cookie, nil)
+	f := t.p.NewField(r, "form")
+	c.Assert(gforms.IsFieldValid(f, token), Equals, true)
+
+	f = t.p.NewField(r, "other")
+	c.Assert(gforms.IsFieldValid(f, token), Equals, false)
+	c.Assert(errors.Is(f.ValidationError(), ErrTokenInvalid), Equals, true)
+	c.Assert(f.ValidationError(), ErrorMatches, "This form is invalid, reload the page and try again")
+	var verr *gforms.ValidationError
+	c.Assert(errors.As(f.ValidationError(), &verr), Equals, true)
+	c.Assert(verr.Code, Equals, CodeTokenInvalid)
+
+	c.Assert(gforms.IsFieldValid(f, nil), Equals, false)
+	c.Assert(f.ValidationError(), Equals, gforms.ErrRequired)
+
+	t.p.now = func() time.Time { return time.Now().Add(DefaultMaxAge + time.Minute) }
+	f = t.p.NewField(r, "form")
+	c.Assert(gforms.IsFieldValid(f, token), Equals, false)
+	c.Assert(errors.Is(f.ValidationError(), ErrTokenExpired), Equals, true)
+	catalog := gforms.NewCatalog()
+	catalog.SetMessages("de", map[string]string{CodeTokenExpired: "Das Formular ist abgelaufen"})
+	c.Assert(gforms.TranslateError(catalog.Translator("de"), f.ValidationError()), Equals, "Das Formular ist abgelaufen")
+}
+
+func (t *CSRFTest) TestKeyRotation(c *C) {
+	cookie := t.get(c)
+	token := t.token
+
+	t.p.Keys = [][]byte{[]byte("new"), []byte("secret")}
+	c.Assert(t.do(post(cookie, url.Values{"_csrf": {token}})).Code, Equals, http.StatusOK)
+
+	t.p.Keys = [][]byte{[]byte("new")}
+	c.Assert(t.do(post(cookie, url.Values{"_csrf": {token}})).Code, Equals, http.StatusForbidden)
+}
+
+func (t *CSRFTest) TestNoKeys(c *C) {
+	c.Assert(func() { New() }, PanicMatches, "csrf: at least one key is required")
+
+	p := &Protector{}
+	c.Assert(func() { p.Handler(http.NotFoundHandler()) }, PanicMatches, "csrf: Protector has no keys")
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	c.Assert(func() { p.Token(r, "") }, PanicMatches, "csrf: Protector has no keys")
+}
+
+func (t *CSRFTest) TestRender(c *C) {
+	var html template.HTML
+	w := httptest.NewRecorder()
+	t.p.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := t.p.NewField(r, "form")
+		t.token = f.Value()
+		html = f.Render()
+	})).ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/", nil))
+
+	c.Assert(html, Equals, template.HTML(`<input type="hidden" id="_csrf" name="_csrf" value="`+t.token+`" />`))
+}
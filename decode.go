@@ -0,0 +1,213 @@
+package gforms
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Decode copies values of form fields to the matching fields of struct
+// pointed to by dst. Struct field matches form field when its name (or
+// name from `gforms:"name"` tag) is equal to the form field name without
+// form prefix. Fields without match are ignored. Decode should be called
+// after form is validated. Nested forms are decoded into struct (or pointer to struct)
+// fields with the same name.
+func Decode(form Form, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("gforms: Decode expects pointer to struct, got %T", dst)
+	}
+	v = v.Elem()
+	minfo := tinfoMap.ModelInfo(v.Type())
+
+	for _, f := range form.FieldList() {
+		mfinfo, ok := minfo.fields[strings.TrimPrefix(f.Name(), form.Prefix())]
+		if !ok {
+			continue
+		}
+		valueMethod := reflect.ValueOf(f).MethodByName("Value")
+		if !valueMethod.IsValid() || valueMethod.Type().NumIn() != 0 || valueMethod.Type().NumOut() != 1 {
+			continue
+		}
+
+		fv := v.FieldByIndex(mfinfo.idx)
+		if err := convertValue(fv, valueMethod.Call(nil)[0]); err != nil {
+			return fmt.Errorf("gforms: can't decode field %s into %s.%s: %v", f.Name(), v.Type(), mfinfo.name, err)
+		}
+	}
+
+	for _, nested := range nestedForms(form) {
+		mfinfo, ok := minfo.fields[nested.name]
+		if !ok {
+			continue
+		}
+		fv := v.FieldByIndex(mfinfo.idx)
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+		if err := Decode(nested.form, fv.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetInitialFrom sets initial values of form fields from the matching
+// fields of src, which is struct or pointer to struct. Fields are matched
+// the same way as in Decode, including nested forms.
+func SetInitialFrom(form Form, src interface{}) error {
+	v := reflect.ValueOf(src)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("gforms: SetInitialFrom expects struct, got %T", src)
+	}
+	minfo := tinfoMap.ModelInfo(v.Type())
+
+	for _, f := range form.FieldList() {
+		mfinfo, ok := minfo.fields[strings.TrimPrefix(f.Name(), form.Prefix())]
+		if !ok {
+			continue
+		}
+		setInitial := reflect.ValueOf(f).MethodByName("SetInitial")
+		if !setInitial.IsValid() || setInitial.Type().NumIn() != 1 {
+			continue
+		}
+
+		fv := v.FieldByIndex(mfinfo.idx)
+		if fv.Kind() == reflect.Ptr && fv.IsNil() && fv.Type() != setInitial.Type().In(0) {
+			continue
+		}
+
+		initial := reflect.New(setInitial.Type().In(0)).Elem()
+		if err := convertValue(initial, fv); err != nil {
+			return fmt.Errorf("gforms: can't set initial value of field %s from %s.%s: %v", f.Name(), v.Type(), mfinfo.name, err)
+		}
+		setInitial.Call([]reflect.Value{initial})
+	}
+
+	for _, nested := range nestedForms(form) {
+		mfinfo, ok := minfo.fields[nested.name]
+		if !ok {
+			continue
+		}
+		fv := v.FieldByIndex(mfinfo.idx)
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		}
+		if reflect.Indirect(fv).Kind() != reflect.Struct {
+			continue
+		}
+		if err := SetInitialFrom(nested.form, fv.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertValue stores src in dst converting between compatible types.
+// Numbers are checked for overflow; kinds that can't be converted without
+// loss of meaning (e.g. int64 to string) are reported as error.
+func convertValue(dst, src reflect.Value) error {
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		return convertValue(dst, src.Elem())
+	}
+	if dst.Kind() == reflect.Ptr {
+		v := reflect.New(dst.Type().Elem())
+		if err := convertValue(v.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		if src.Kind() == reflect.String {
+			dst.SetString(src.String())
+			return nil
+		}
+	case reflect.Bool:
+		if src.Kind() == reflect.Bool {
+			dst.SetBool(src.Bool())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n := src.Int()
+			if dst.OverflowInt(n) {
+				return fmt.Errorf("value %d overflows %s", n, dst.Type())
+			}
+			dst.SetInt(n)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n := src.Uint()
+			if n > 1<<63-1 || dst.OverflowInt(int64(n)) {
+				return fmt.Errorf("value %d overflows %s", n, dst.Type())
+			}
+			dst.SetInt(int64(n))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n := src.Int()
+			if n < 0 || dst.OverflowUint(uint64(n)) {
+				return fmt.Errorf("value %d overflows %s", n, dst.Type())
+			}
+			dst.SetUint(uint64(n))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n := src.Uint()
+			if dst.OverflowUint(n) {
+				return fmt.Errorf("value %d overflows %s", n, dst.Type())
+			}
+			dst.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(src.Float())
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetFloat(float64(src.Int()))
+			return nil
+		}
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice {
+			break
+		}
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		s := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := convertValue(s.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(s)
+		return nil
+	}
+
+	return fmt.Errorf("type %s can't be converted to %s", src.Type(), dst.Type())
+}
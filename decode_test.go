@@ -0,0 +1,152 @@
+package gforms_test
+
+import (
+	"net/url"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type DecodeTest struct{}
+
+var _ = Suite(&DecodeTest{})
+
+//------------------------------------------------------------------------------
+
+type ArticleForm struct {
+	*gforms.BaseForm
+	Title    *gforms.StringField
+	Rating   *gforms.Int64Field
+	IsPublic *gforms.BoolField
+	Tags     *gforms.MultiStringChoiceField
+}
+
+func NewArticleForm() *ArticleForm {
+	f := &ArticleForm{
+		BaseForm: &gforms.BaseForm{},
+	}
+	gforms.InitForm(f)
+	f.Tags.SetChoices([]gforms.StringChoice{{"go", "Go"}, {"web", "Web"}})
+	return f
+}
+
+type Timestamps struct {
+	Created int64
+}
+
+type Article struct {
+	Timestamps
+	Title    string
+	Score    uint8 `gforms:"Rating"`
+	Public   *bool `gforms:"IsPublic"`
+	Tags     []string
+	internal string
+	Ignored  string `gforms:"-"`
+}
+
+//------------------------------------------------------------------------------
+
+func (t *DecodeTest) TestDecode(c *C) {
+	f := NewArticleForm()
+	f.Title.SetInitial("Hello")
+	f.Rating.SetInitial(5)
+	f.IsPublic.SetInitial(true)
+	f.Tags.SetInitial([]string{"go"})
+
+	article := &Article{}
+	c.Assert(gforms.Decode(f, article), IsNil)
+	c.Assert(article.Title, Equals, "Hello")
+	c.Assert(article.Score, Equals, uint8(5))
+	c.Assert(*article.Public, Equals, true)
+	c.Assert(article.Tags, DeepEquals, []string{"go"})
+}
+
+func (t *DecodeTest) TestDecodeOverflow(c *C) {
+	f := NewArticleForm()
+	f.Rating.SetInitial(256)
+
+	err := gforms.Decode(f, &Article{})
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "gforms: can't decode field Rating into gforms_test.Article.Score: value 256 overflows uint8")
+}
+
+func (t *DecodeTest) TestDecodeTypeMismatch(c *C) {
+	f := NewArticleForm()
+
+	model := &struct{ Title int }{}
+	err := gforms.Decode(f, model)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, "gforms: can't decode field Title into .*: type string can't be converted to int")
+}
+
+func (t *DecodeTest) TestDecodeRequiresPointer(c *C) {
+	err := gforms.Decode(NewArticleForm(), Article{})
+	c.Assert(err, ErrorMatches, "gforms: Decode expects pointer to struct, got gforms_test.Article")
+}
+
+func (t *DecodeTest) TestSetInitialFrom(c *C) {
+	f := NewArticleForm()
+	article := &Article{
+		Title: "Hello",
+		Score: 3,
+		Tags:  []string{"go", "web"},
+	}
+
+	c.Assert(gforms.SetInitialFrom(f, article), IsNil)
+	c.Assert(f.Title.Value(), Equals, "Hello")
+	c.Assert(f.Rating.Value(), Equals, int64(3))
+	c.Assert(f.IsPublic.Value(), Equals, false)
+	c.Assert(f.Tags.Value(), DeepEquals, []string{"go", "web"})
+}
+
+func (t *DecodeTest) TestRoundTrip(c *C) {
+	f := NewArticleForm()
+	ok := gforms.IsFormValid(f, url.Values{
+		"Title":    {"Hello"},
+		"Rating":   {"4"},
+		"IsPublic": {"true"},
+	})
+	c.Assert(ok, Equals, true)
+
+	article := &Article{}
+	c.Assert(gforms.Decode(f, article), IsNil)
+
+	f2 := NewArticleForm()
+	c.Assert(gforms.SetInitialFrom(f2, article), IsNil)
+	c.Assert(f2.Title.Value(), Equals, "Hello")
+	c.Assert(f2.Rating.Value(), Equals, int64(4))
+	c.Assert(f2.IsPublic.Value(), Equals, true)
+}
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type Order struct {
+	Name     string
+	Billing  Address
+	Shipping *Address
+}
+
+func (t *DecodeTest) TestDecodeNestedForm(c *C) {
+	f := NewOrderForm()
+	c.Assert(gforms.IsFormValid(f, url.Values{
+		"Name":            {"foo"},
+		"Billing.Street":  {"Main st."},
+		"Billing.City":    {"Springfield"},
+		"shipping-Street": {"Elm st."},
+		"shipping-City":   {"Shelbyville"},
+	}), Equals, true)
+
+	order := &Order{}
+	c.Assert(gforms.Decode(f, order), IsNil)
+	c.Assert(order.Name, Equals, "foo")
+	c.Assert(order.Billing, DeepEquals, Address{"Main st.", "Springfield"})
+	c.Assert(order.Shipping, DeepEquals, &Address{"Elm st.", "Shelbyville"})
+
+	f = NewOrderForm()
+	c.Assert(gforms.SetInitialFrom(f, order), IsNil)
+	c.Assert(f.Shipping.City.Value(), Equals, "Shelbyville")
+}
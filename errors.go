@@ -0,0 +1,178 @@
+package gforms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error codes of the built-in validation errors. Every code has its own
+// message, so codes can be used as Translator keys.
+const (
+	CodeRequired          = "required"
+	CodeInvalidInteger    = "invalid_integer"
+	CodeInvalidNumber     = "invalid_number"
+	CodeInvalidDateTime   = "invalid_datetime"
+	CodeUnsupportedType   = "unsupported_type"
+	CodeMinLength         = "min_length"
+	CodeMaxLength         = "max_length"
+	CodeMinValue          = "min_value"
+	CodeMaxValue          = "max_value"
+	CodeMinDateTime       = "min_datetime"
+	CodeMaxDateTime       = "max_datetime"
+	CodeStep              = "step"
+	CodeMaxDigits         = "max_digits"
+	CodeMaxDecimalPlaces  = "max_decimal_places"
+	CodeInvalidChoice     = "invalid_choice"
+	CodePattern           = "pattern"
+	CodeMinFileSize       = "min_file_size"
+	CodeMaxFileSize       = "max_file_size"
+	CodeInvalidEmail      = "invalid_email"
+	CodeInvalidURL        = "invalid_url"
+	CodeURLScheme         = "url_scheme"
+	CodeURLHost           = "url_host"
+	CodeFileExtension     = "file_extension"
+	CodeFileType          = "file_type"
+	CodeReadFile          = "read_file"
+	CodeInvalidImage      = "invalid_image"
+	CodeImageFormat       = "image_format"
+	CodeMinImageWidth     = "min_image_width"
+	CodeMaxImageWidth     = "max_image_width"
+	CodeMinImageHeight    = "min_image_height"
+	CodeMaxImageHeight    = "max_image_height"
+	CodeAspectRatio       = "aspect_ratio"
+	CodeMinFiles          = "min_files"
+	CodeMaxFiles          = "max_files"
+	CodeInvalidStoredFile = "invalid_stored_file"
+	CodeStoredFileExpired = "stored_file_expired"
+	CodeSaveFile          = "save_file"
+)
+
+var (
+	ErrRequired = NewValidationError(CodeRequired, "This field is required", nil)
+)
+
+// ValidationError describes why value did not pass validation. Message
+// may refer to params as {name}, e.g. "at least {min} symbols".
+type ValidationError struct {
+	Code    string
+	Message string
+	Params  map[string]interface{}
+}
+
+func NewValidationError(code, message string, params map[string]interface{}) *ValidationError {
+	return &ValidationError{
+		Code:    code,
+		Message: message,
+		Params:  params,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	return formatMessage(e.Message, e.Params)
+}
+
+func errUnsupportedType(value interface{}) *ValidationError {
+	return NewValidationError(
+		CodeUnsupportedType,
+		"Type {type} is not supported",
+		map[string]interface{}{"type": fmt.Sprintf("%T", value)},
+	)
+}
+
+// formatMessage replaces {name} placeholders in msg with params.
+// Unknown placeholders are left as is.
+func formatMessage(msg string, params map[string]interface{}) string {
+	if len(params) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+
+	b := &strings.Builder{}
+	for {
+		start := strings.IndexByte(msg, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(msg[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(msg[:start])
+		if param, ok := params[msg[start+1:end]]; ok {
+			fmt.Fprint(b, param)
+		} else {
+			b.WriteString(msg[start : end+1])
+		}
+		msg = msg[end+1:]
+	}
+	b.WriteString(msg)
+	return b.String()
+}
+
+//------------------------------------------------------------------------------
+
+// ValidationErrors is list of errors found while validating one value.
+type ValidationErrors []error
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Add appends err to the list. ValidationErrors are flattened.
+func (errs *ValidationErrors) Add(err error) {
+	if err == nil {
+		return
+	}
+	var list ValidationErrors
+	if errors.As(err, &list) {
+		*errs = append(*errs, list...)
+		return
+	}
+	*errs = append(*errs, err)
+}
+
+// Err returns nil when list is empty and list itself otherwise.
+func (errs ValidationErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ErrorList returns errors contained in err: elements of ValidationErrors
+// or err itself.
+func ErrorList(err error) []error {
+	if err == nil {
+		return nil
+	}
+	var list ValidationErrors
+	if errors.As(err, &list) {
+		return list
+	}
+	return []error{err}
+}
+
+//------------------------------------------------------------------------------
+
+// FileError is validation error of one of the files uploaded to
+// MultiFileField. TranslateError translates Err and keeps the filename.
+type FileError struct {
+	// Index is position of the file among uploaded files.
+	Index    int
+	Filename string
+	Err      error
+}
+
+func (e *FileError) Error() string {
+	return e.Filename + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
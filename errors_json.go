@@ -0,0 +1,143 @@
+package gforms
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// FieldError is JSON representation of a validation error.
+type FieldError struct {
+	// Field is name of the field; empty for non-field errors.
+	Field   string                 `json:"field,omitempty"`
+	Message string                 `json:"message"`
+	Code    string                 `json:"code,omitempty"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// ErrorsBody is JSON representation of form errors:
+//
+//	{
+//	  "errors": [
+//	    {"field": "Title", "message": "...", "code": "min_length", "params": {"min": 3}}
+//	  ],
+//	  "non_field_errors": [
+//	    {"message": "...", "code": "..."}
+//	  ]
+//	}
+//
+// Field errors are ordered as form fields. Messages are translated with
+// form translator.
+type ErrorsBody struct {
+	Errors         []FieldError `json:"errors"`
+	NonFieldErrors []FieldError `json:"non_field_errors"`
+}
+
+func NewErrorsBody(form Form) *ErrorsBody {
+	body := &ErrorsBody{
+		Errors:         make([]FieldError, 0),
+		NonFieldErrors: make([]FieldError, 0),
+	}
+	formErrors := form.Errors()
+
+	for _, err := range formErrors[""] {
+		body.NonFieldErrors = append(body.NonFieldErrors, newFieldError("", form.Translator(), err))
+	}
+
+	seen := map[string]bool{"": true}
+	for _, field := range form.FieldList() {
+		seen[field.Name()] = true
+		for _, err := range formErrors[field.Name()] {
+			body.Errors = append(body.Errors, newFieldError(field.Name(), field.Translator(), err))
+		}
+	}
+
+	// Errors added with AddError for names that are not form fields.
+	names := make([]string, 0)
+	for name := range formErrors {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, err := range formErrors[name] {
+			body.Errors = append(body.Errors, newFieldError(name, form.Translator(), err))
+		}
+	}
+
+	return body
+}
+
+func newFieldError(name string, t Translator, err error) FieldError {
+	fe := FieldError{
+		Field:   name,
+		Message: TranslateError(t, err),
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		fe.Code = verr.Code
+		fe.Params = verr.Params
+	}
+	var ferr *FileError
+	if errors.As(err, &ferr) {
+		params := make(map[string]interface{}, len(fe.Params)+2)
+		for k, v := range fe.Params {
+			params[k] = v
+		}
+		params["file"] = ferr.Filename
+		params["index"] = ferr.Index
+		fe.Params = params
+	}
+	return fe
+}
+
+// ServeHTTP writes errors as JSON with 422 Unprocessable Entity status.
+func (b *ErrorsBody) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, "application/json", http.StatusUnprocessableEntity, b)
+}
+
+// ErrorsJSON returns JSON representation of form errors (see ErrorsBody).
+func ErrorsJSON(form Form) ([]byte, error) {
+	return json.Marshal(NewErrorsBody(form))
+}
+
+//------------------------------------------------------------------------------
+
+// Problem is RFC 7807 problem details object with form errors as
+// extension members "errors" and "non_field_errors".
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	*ErrorsBody
+}
+
+// NewProblem returns problem with "about:blank" type and 422 status.
+func NewProblem(form Form) *Problem {
+	return &Problem{
+		Type:       "about:blank",
+		Title:      "Validation failed",
+		Status:     http.StatusUnprocessableEntity,
+		ErrorsBody: NewErrorsBody(form),
+	}
+}
+
+// ServeHTTP writes problem as application/problem+json.
+func (p *Problem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, "application/problem+json", p.Status, p)
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,59 @@
+package gforms_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type ErrorsJSONTest struct{}
+
+var _ = Suite(&ErrorsJSONTest{})
+
+func (t *ErrorsJSONTest) TestErrorsJSON(c *C) {
+	f := NewPasswordForm()
+	f.Password.SetIsRequired(true)
+	f.ConfirmPassword.MinLen = 3
+
+	gforms.IsFormValid(f, url.Values{"ConfirmPassword": {"x"}})
+
+	b, err := gforms.ErrorsJSON(f)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"errors":[`+
+		`{"field":"Password","message":"This field is required","code":"required"},`+
+		`{"field":"ConfirmPassword","message":"This field should have at least 3 symbols","code":"min_length","params":{"min":3}}],`+
+		`"non_field_errors":[{"message":"Password is not set"}]}`)
+}
+
+func (t *ErrorsJSONTest) TestEmptyErrors(c *C) {
+	b, err := gforms.ErrorsJSON(NewPasswordForm())
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"errors":[],"non_field_errors":[]}`)
+}
+
+func (t *ErrorsJSONTest) TestProblem(c *C) {
+	f := NewPasswordForm()
+	gforms.IsFormValid(f, url.Values{})
+
+	w := httptest.NewRecorder()
+	gforms.NewProblem(f).ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+	c.Assert(w.Code, Equals, 422)
+	c.Assert(w.Header().Get("Content-Type"), Equals, "application/problem+json")
+	c.Assert(w.Body.String(), Equals, `{"type":"about:blank","title":"Validation failed","status":422,`+
+		`"errors":[],"non_field_errors":[{"message":"Password is not set"}]}`)
+}
+
+func (t *ErrorsJSONTest) TestErrorsBodyHandler(c *C) {
+	f := NewPasswordForm()
+	gforms.IsFormValid(f, url.Values{})
+
+	w := httptest.NewRecorder()
+	gforms.NewErrorsBody(f).ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+	c.Assert(w.Code, Equals, 422)
+	c.Assert(w.Header().Get("Content-Type"), Equals, "application/json")
+}
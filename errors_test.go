@@ -0,0 +1,78 @@
+package gforms_test
+
+import (
+	"errors"
+	"net/url"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type ErrorsTest struct{}
+
+var _ = Suite(&ErrorsTest{})
+
+func (t *ErrorsTest) TestValidationError(c *C) {
+	err := gforms.NewValidationError(
+		"min_length",
+		"At least {min} symbols, {unknown}",
+		map[string]interface{}{"min": 3},
+	)
+	c.Assert(err.Error(), Equals, "At least 3 symbols, {unknown}")
+}
+
+func (t *ErrorsTest) TestRequiredCode(c *C) {
+	f := gforms.NewStringField()
+	f.SetIsRequired(true)
+
+	c.Assert(gforms.IsFieldValid(f, ""), Equals, false)
+	c.Assert(f.ValidationError(), Equals, gforms.ErrRequired)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeRequired)
+}
+
+func (t *ErrorsTest) TestAllErrorsAreReported(c *C) {
+	f := gforms.NewStringField()
+	f.MinLen = 5
+	f.AddValidator(gforms.NewPatternValidator("[0-9]+"))
+	f.AddValidator(gforms.NewStringChoicesValidator([]gforms.StringChoice{{"12345", ""}}))
+
+	c.Assert(gforms.IsFieldValid(f, "abc"), Equals, false)
+
+	errs := f.ValidationErrors()
+	c.Assert(errs, HasLen, 3)
+
+	codes := make([]string, 0)
+	for _, err := range errs {
+		var verr *gforms.ValidationError
+		c.Assert(errors.As(err, &verr), Equals, true)
+		codes = append(codes, verr.Code)
+	}
+	c.Assert(codes, DeepEquals, []string{
+		gforms.CodeMinLength,
+		gforms.CodePattern,
+		gforms.CodeInvalidChoice,
+	})
+	c.Assert(errs[0].(*gforms.ValidationError).Params, DeepEquals, map[string]interface{}{"min": 5})
+	c.Assert(errs[2].Error(), Equals, "abc is invalid choice")
+}
+
+func (t *ErrorsTest) TestMultiFieldReportsEveryInvalidValue(c *C) {
+	f := gforms.NewMultiSelectStringField()
+	f.SetChoices([]gforms.StringChoice{{"foo", "bar"}})
+
+	c.Assert(gforms.IsFieldValid(f, []interface{}{"x", "foo", "y"}), Equals, false)
+	c.Assert(f.ValidationErrors(), HasLen, 2)
+	c.Assert(f.ValidationErrors()[1].Error(), Equals, "y is invalid choice")
+}
+
+func (t *ErrorsTest) TestFormErrors(c *C) {
+	f := NewPasswordForm()
+	f.Password.MinLen = 5
+	f.Password.MaxLen = 1
+
+	ok := gforms.IsFormValid(f, url.Values{"Password": {"abc"}})
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Errors()["Password"], HasLen, 2)
+	c.Assert(f.Errors()[""], HasLen, 1)
+}
@@ -0,0 +1,116 @@
+package gforms_test
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type EscapeTest struct{}
+
+var _ = Suite(&EscapeTest{})
+
+const hostile = `"><script>alert(1)</script>`
+
+func assertEscaped(c *C, html template.HTML) {
+	c.Assert(strings.Contains(string(html), "<script>"), Equals, false, Commentf("%s", html))
+	c.Assert(strings.Contains(string(html), hostile), Equals, false, Commentf("%s", html))
+}
+
+type HostileForm struct {
+	*gforms.BaseForm
+	Token *gforms.StringField
+	Lang  *gforms.StringChoiceField
+	Tags  *gforms.MultiStringChoiceField `gforms:",widget=checkboxgroup"`
+	Kind  *gforms.StringChoiceField      `gforms:",widget=radio"`
+	Agree *gforms.BoolField
+	Title *gforms.StringField
+}
+
+func NewHostileForm() *HostileForm {
+	f := &HostileForm{
+		BaseForm: &gforms.BaseForm{},
+		Token:    gforms.NewStringField(),
+	}
+	f.Token.SetWidget(gforms.NewHiddenWidget())
+	gforms.InitForm(f)
+
+	choices := []gforms.StringChoice{{hostile, hostile}}
+	f.Lang.SetChoices(choices)
+	f.Tags.SetChoices(choices)
+	f.Kind.SetChoices(choices)
+	for _, field := range f.FieldList() {
+		field.SetLabel(hostile)
+		field.SetHelpText(hostile)
+	}
+	f.Title.Widget().Attrs().Set("placeholder", hostile)
+	return f
+}
+
+func (t *EscapeTest) TestHostileValues(c *C) {
+	f := NewHostileForm()
+
+	gforms.IsFormValid(f, url.Values{
+		"Token": {hostile},
+		"Lang":  {hostile + "x"},
+		"Kind":  {hostile + "x"},
+		"Title": {hostile},
+	})
+	f.AddError("", gforms.NewValidationError("custom", hostile, nil))
+	f.AddError("Token", gforms.NewValidationError("custom", hostile, nil))
+
+	for _, theme := range []gforms.Theme{
+		gforms.LegacyTheme,
+		gforms.Bootstrap5Theme,
+		gforms.TailwindTheme,
+		gforms.SemanticTheme,
+	} {
+		f.SetTheme(theme)
+
+		html, err := gforms.RenderForm(f)
+		c.Assert(err, IsNil)
+		assertEscaped(c, html)
+
+		for _, field := range f.FieldList() {
+			assertEscaped(c, field.Render())
+			assertEscaped(c, field.Render("class", hostile, hostile, "x"))
+
+			html, err := gforms.RenderLabel(field)
+			c.Assert(err, IsNil)
+			assertEscaped(c, html)
+
+			html, err = gforms.RenderError(field)
+			c.Assert(err, IsNil)
+			assertEscaped(c, html)
+		}
+	}
+}
+
+func (t *EscapeTest) TestTrustedHTML(c *C) {
+	f := gforms.NewStringField()
+	f.SetName("title")
+	f.SetLabelHTML(`<b>Title</b>`)
+	f.SetHelpTextHTML(`<a href="/help">Help</a>`)
+
+	html, err := gforms.RenderLabel(f)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<label class="control-label" for="title"><b>Title</b></label>`))
+
+	html, err = gforms.RenderHelpText(f)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<p class="help-block"><a href="/help">Help</a></p>`))
+}
+
+func (t *EscapeTest) TestSelectedValueWithSpecialChars(c *C) {
+	f := gforms.NewSelectStringField()
+	f.SetChoices([]gforms.StringChoice{{"a&b", "A & B"}})
+	f.SetInitial("a&b")
+
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<select><option value="a&amp;b" selected="selected">A &amp; B</option></select>`,
+	))
+}
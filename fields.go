@@ -1,18 +1,59 @@
 package gforms
 
 import (
-	"errors"
 	"fmt"
 	"html/template"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/big"
 	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
-	ErrRequired = errors.New("This field is required")
+	errNotInteger = NewValidationError(CodeInvalidInteger, "This field should be an integer", nil)
+	errNotNumber  = NewValidationError(CodeInvalidNumber, "This field should be a number", nil)
+
+	errInvalidEmail = NewValidationError(CodeInvalidEmail, "Enter a valid email address", nil)
+	errInvalidURL   = NewValidationError(CodeInvalidURL, "Enter a valid URL", nil)
+	errInvalidImage = NewValidationError(CodeInvalidImage, "Upload a valid image (PNG, JPEG or GIF)", nil)
 )
 
+func errMinValue(min interface{}) *ValidationError {
+	return NewValidationError(
+		CodeMinValue,
+		"This field should be greater than or equal to {min}",
+		map[string]interface{}{"min": min},
+	)
+}
+
+func errMaxValue(max interface{}) *ValidationError {
+	return NewValidationError(
+		CodeMaxValue,
+		"This field should be less than or equal to {max}",
+		map[string]interface{}{"max": max},
+	)
+}
+
+func errStep(step interface{}) *ValidationError {
+	return NewValidationError(
+		CodeStep,
+		"This field should be a multiple of {step}",
+		map[string]interface{}{"step": step},
+	)
+}
+
 //------------------------------------------------------------------------------
 
 type Field interface {
@@ -25,6 +66,25 @@ type Field interface {
 	HasLabel() bool
 	SetLabel(string)
 	Label() string
+	SetLabelHTML(template.HTML)
+	LabelHTML() template.HTML
+
+	SetHelpText(string)
+	HelpText() string
+	SetHelpTextHTML(template.HTML)
+	HelpTextHTML() template.HTML
+
+	SetTranslator(Translator)
+	Translator() Translator
+
+	SetTemplates(*TemplateSet)
+	Templates() *TemplateSet
+
+	SetTheme(Theme)
+	Theme() Theme
+
+	SetHTML5Attrs([]string)
+	HTML5Attrs() []string
 
 	SetWidget(Widget)
 	Widget() Widget
@@ -41,7 +101,9 @@ type Field interface {
 
 	HasValidationError() bool
 	SetValidationError(error)
+	AddValidationError(error)
 	ValidationError() error
+	ValidationErrors() []error
 
 	Reset()
 	Render(...string) template.HTML
@@ -70,6 +132,11 @@ func isEmpty(value interface{}) bool {
 func IsFieldValid(f Field, rawValue interface{}) bool {
 	f.Reset()
 
+	if v, ok := rawValue.(invalidRawValue); ok {
+		f.SetValidationError(v.err)
+		return false
+	}
+
 	if rawValue == nil || isEmpty(rawValue) {
 		if f.IsRequired() {
 			f.SetValidationError(ErrRequired)
@@ -87,6 +154,12 @@ func IsFieldValid(f Field, rawValue interface{}) bool {
 	return true
 }
 
+// invalidRawValue is returned by value getters for submitted values that
+// can't be passed to the field, e.g. JSON object for StringField.
+type invalidRawValue struct {
+	err error
+}
+
 type SingleValueField interface {
 	StringValue() string
 }
@@ -98,19 +171,27 @@ type MultiValueField interface {
 //------------------------------------------------------------------------------
 
 type BaseField struct {
-	hasName  bool
-	name     string
-	hasLabel bool
-	label    string
-	widget   Widget
+	hasName      bool
+	name         string
+	hasLabel     bool
+	label        string
+	labelHTML    template.HTML
+	helpText     string
+	helpTextHTML template.HTML
+	widget       Widget
+
+	translator Translator
+	templates  *TemplateSet
+	theme      Theme
+	html5Attrs []string
 
 	isMulti     bool
 	isMultipart bool
 	isRequired  bool
 
-	validators      []Validator
-	validationError error
-	iValue          interface{}
+	validators       []Validator
+	validationErrors ValidationErrors
+	iValue           interface{}
 }
 
 func (f *BaseField) HasName() bool {
@@ -142,6 +223,62 @@ func (f *BaseField) Label() string {
 	return f.label
 }
 
+// SetLabelHTML sets trusted HTML that is rendered instead of the label
+// without escaping.
+func (f *BaseField) SetLabelHTML(html template.HTML) {
+	f.hasLabel = true
+	f.labelHTML = html
+}
+
+func (f *BaseField) LabelHTML() template.HTML {
+	return f.labelHTML
+}
+
+func (f *BaseField) SetHelpText(text string) {
+	f.helpText = text
+}
+
+func (f *BaseField) HelpText() string {
+	return f.helpText
+}
+
+// SetHelpTextHTML sets trusted HTML that is rendered instead of the help
+// text without escaping.
+func (f *BaseField) SetHelpTextHTML(html template.HTML) {
+	f.helpTextHTML = html
+}
+
+func (f *BaseField) HelpTextHTML() template.HTML {
+	return f.helpTextHTML
+}
+
+func (f *BaseField) SetTranslator(t Translator) {
+	f.translator = t
+}
+
+func (f *BaseField) Translator() Translator {
+	return f.translator
+}
+
+func (f *BaseField) SetTemplates(set *TemplateSet) {
+	f.templates = set
+}
+
+// Templates returns template set used by Render or nil when default
+// templates are used.
+func (f *BaseField) Templates() *TemplateSet {
+	return f.templates
+}
+
+func (f *BaseField) SetTheme(theme Theme) {
+	f.theme = theme
+}
+
+// Theme returns theme used by Render or nil when default theme is used.
+func (f *BaseField) Theme() Theme {
+	return f.theme
+}
+
 func (f *BaseField) SetWidget(widget Widget) {
 	f.widget = widget
 }
@@ -181,13 +318,28 @@ func (f *BaseField) AddValidator(validator Validator) {
 	f.validators = append(f.validators, validator)
 }
 
+// replaceValidator replaces validator of the same type as validator or
+// adds it, e.g. so choices validator is not duplicated when choices are
+// set again.
+func (f *BaseField) replaceValidator(validator Validator) {
+	typ := reflect.TypeOf(validator)
+	for i, v := range f.validators {
+		if reflect.TypeOf(v) == typ {
+			f.validators[i] = validator
+			return
+		}
+	}
+	f.AddValidator(validator)
+}
+
+// ApplyValidators applies all validators to the value and returns
+// ValidationErrors with every error found.
 func (f *BaseField) ApplyValidators(rawValue interface{}) error {
+	var errs ValidationErrors
 	for _, validator := range f.validators {
-		if err := validator.Validate(rawValue); err != nil {
-			return err
-		}
+		errs.Add(validator.Validate(rawValue))
 	}
-	return nil
+	return errs.Err()
 }
 
 func (f *BaseField) validate(rawValue interface{}) error {
@@ -195,15 +347,30 @@ func (f *BaseField) validate(rawValue interface{}) error {
 }
 
 func (f *BaseField) HasValidationError() bool {
-	return f.validationError != nil
+	return len(f.validationErrors) > 0
 }
 
+// SetValidationError replaces field errors with err, which may be
+// ValidationErrors.
 func (f *BaseField) SetValidationError(err error) {
-	f.validationError = err
+	f.validationErrors = nil
+	f.validationErrors.Add(err)
 }
 
+func (f *BaseField) AddValidationError(err error) {
+	f.validationErrors.Add(err)
+}
+
+// ValidationError returns first validation error.
 func (f *BaseField) ValidationError() error {
-	return f.validationError
+	if len(f.validationErrors) == 0 {
+		return nil
+	}
+	return f.validationErrors[0]
+}
+
+func (f *BaseField) ValidationErrors() []error {
+	return f.validationErrors
 }
 
 func (f *BaseField) StringValue() string {
@@ -215,13 +382,70 @@ func (f *BaseField) StringValue() string {
 
 func (f *BaseField) Reset() {
 	f.iValue = nil
-	f.validationError = nil
+	f.validationErrors = nil
 }
 
 func (f *BaseField) Render(attrs ...string) template.HTML {
 	panic("not implemented")
 }
 
+// NoHTML5Attrs disables HTML5 constraint attributes when passed to
+// SetHTML5Attrs.
+var NoHTML5Attrs = []string{}
+
+// SetHTML5Attrs limits HTML5 constraint attributes (required, minlength,
+// maxlength, min, max, step, pattern and type) rendered by the field to
+// names. Nil enables all attributes.
+func (f *BaseField) SetHTML5Attrs(names []string) {
+	f.html5Attrs = names
+}
+
+func (f *BaseField) HTML5Attrs() []string {
+	return f.html5Attrs
+}
+
+func (f *BaseField) isHTML5AttrEnabled(name string) bool {
+	if f.html5Attrs == nil {
+		return true
+	}
+	for _, enabled := range f.html5Attrs {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withHTML5Attrs returns enabled constraint attributes followed by attrs,
+// so attributes passed to Render take precedence.
+func (f *BaseField) withHTML5Attrs(constraints []string, attrs []string) []string {
+	if f.widget.IsHidden() {
+		return attrs
+	}
+
+	all := make([]string, 0, 2+len(constraints))
+	if _, isGroup := f.widget.(*CheckboxGroupWidget); f.isRequired && !isGroup {
+		all = append(all, "required", "required")
+	}
+	all = append(all, constraints...)
+
+	result := make([]string, 0, len(all)+len(attrs))
+	for i := 0; i+1 < len(all); i += 2 {
+		if f.isHTML5AttrEnabled(all[i]) {
+			result = append(result, all[i], all[i+1])
+		}
+	}
+	return append(result, attrs...)
+}
+
+// inputType returns constraint that changes type of text input to typ.
+func (f *BaseField) inputType(typ string) []string {
+	if _, ok := f.widget.(*TextWidget); ok {
+		return []string{"type", typ}
+	}
+	return nil
+}
+
 //------------------------------------------------------------------------------
 
 type StringField struct {
@@ -239,16 +463,25 @@ func (f *StringField) Value() string {
 func (f *StringField) Validate(rawValue interface{}) error {
 	value := fmt.Sprint(rawValue)
 
+	var errs ValidationErrors
 	valueLen := len(value)
 	if f.MinLen > 0 && valueLen < f.MinLen {
-		return fmt.Errorf("This field should have at least %d symbols", f.MinLen)
+		errs.Add(NewValidationError(
+			CodeMinLength,
+			"This field should have at least {min} symbols",
+			map[string]interface{}{"min": f.MinLen},
+		))
 	}
 	if f.MaxLen > 0 && valueLen > f.MaxLen {
-		return fmt.Errorf("This field should have less than %d symbols", f.MaxLen)
+		errs.Add(NewValidationError(
+			CodeMaxLength,
+			"This field should have less than {max} symbols",
+			map[string]interface{}{"max": f.MaxLen},
+		))
 	}
-
-	if err := f.ApplyValidators(value); err != nil {
-		return err
+	errs.Add(f.ApplyValidators(value))
+	if len(errs) > 0 {
+		return errs
 	}
 
 	f.iValue = value
@@ -259,8 +492,25 @@ func (f *StringField) SetInitial(initial string) {
 	f.iValue = initial
 }
 
+func (f *StringField) html5Constraints() []string {
+	constraints := make([]string, 0, 6)
+	if f.MinLen > 0 {
+		constraints = append(constraints, "minlength", strconv.Itoa(f.MinLen))
+	}
+	if f.MaxLen > 0 {
+		constraints = append(constraints, "maxlength", strconv.Itoa(f.MaxLen))
+	}
+	for _, validator := range f.validators {
+		if v, ok := validator.(*PatternValidator); ok {
+			constraints = append(constraints, "pattern", v.Pattern)
+			break
+		}
+	}
+	return constraints
+}
+
 func (f *StringField) Render(attrs ...string) template.HTML {
-	return f.Widget().Render(attrs, f.StringValue())
+	return f.Widget().Render(f.withHTML5Attrs(f.html5Constraints(), attrs), f.StringValue())
 }
 
 func NewStringField() *StringField {
@@ -287,6 +537,211 @@ func NewTextareaStringField() *TextareaStringField {
 
 //------------------------------------------------------------------------------
 
+// EmailField accepts single email address (without display name) parsed
+// with net/mail. Domain is lowercased and, when IDNA is set, converted to
+// ASCII (punycode), e.g. "user@Bücher.example" becomes
+// "user@xn--bcher-kva.example". Local part that is not a dot-atom is kept
+// quoted, e.g. `"john doe"@example.com`.
+type EmailField struct {
+	*StringField
+	IDNA bool
+}
+
+func (f *EmailField) Validate(rawValue interface{}) error {
+	value := strings.TrimSpace(fmt.Sprint(rawValue))
+
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Name != "" || strings.ContainsAny(value, "<>") {
+		return errInvalidEmail
+	}
+	at := strings.LastIndexByte(addr.Address, '@')
+	local, domain := addr.Address[:at], strings.ToLower(addr.Address[at+1:])
+	if !isDotAtom(local) {
+		local = quoteLocalPart(local)
+	}
+	if f.IDNA {
+		if domain, err = domainToASCII(domain); err != nil {
+			return errInvalidEmail
+		}
+	}
+
+	return f.StringField.Validate(local + "@" + domain)
+}
+
+// isDotAtom reports whether s is dot-atom (RFC 5322), i.e. local part
+// that doesn't need quoting. Non-ASCII runes are allowed (RFC 6532).
+func isDotAtom(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' || strings.Contains(s, "..") {
+		return false
+	}
+	for _, r := range s {
+		if r >= utf8.RuneSelf || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			continue
+		}
+		if !strings.ContainsRune(".!#$%&'*+-/=?^_`{|}~", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteLocalPart returns s as quoted string.
+func quoteLocalPart(s string) string {
+	b := &strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func (f *EmailField) Render(attrs ...string) template.HTML {
+	constraints := append(f.inputType("email"), f.html5Constraints()...)
+	return f.Widget().Render(f.withHTML5Attrs(constraints, attrs), f.StringValue())
+}
+
+func NewEmailField() *EmailField {
+	return &EmailField{
+		StringField: NewStringField(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// URLField accepts URL parsed with net/url. Scheme and host are
+// lowercased, fullwidth characters and ideographic full stops in host
+// are mapped to ASCII and trailing dot is removed from host. By default
+// only absolute http and https URLs are accepted. URLs without host (e.g.
+// "mailto:user@example.com") are accepted only for schemes that don't
+// require host and only when AllowedHosts and DeniedHosts are empty.
+type URLField struct {
+	*StringField
+	// Schemes lists allowed schemes. Default is http and https.
+	Schemes []string
+	// AllowedHosts and DeniedHosts limit host of the URL. Host matches
+	// entry when it is equal to it; entry with leading dot (".example.com")
+	// also matches all subdomains. Empty AllowedHosts allows any host.
+	// When either list is set, internationalized hosts are accepted only
+	// in ASCII (punycode) form and entries should use it too.
+	AllowedHosts, DeniedHosts []string
+	// AllowRelative accepts relative URLs such as "/path" (schemes and
+	// hosts are not checked then).
+	AllowRelative bool
+	// IDNA converts internationalized host to ASCII (punycode).
+	IDNA bool
+}
+
+var defaultURLSchemes = []string{"http", "https"}
+
+// hostURLSchemes lists schemes of URLs that must have host.
+var hostURLSchemes = []string{"http", "https", "ftp", "ftps", "ws", "wss"}
+
+// URL returns parsed value.
+func (f *URLField) URL() *url.URL {
+	u, _ := url.Parse(f.Value())
+	return u
+}
+
+func (f *URLField) Validate(rawValue interface{}) error {
+	value := strings.TrimSpace(fmt.Sprint(rawValue))
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return errInvalidURL
+	}
+	if !u.IsAbs() {
+		if !f.AllowRelative || u.Host != "" {
+			return errInvalidURL
+		}
+		return f.StringField.Validate(u.String())
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	schemes := f.Schemes
+	if len(schemes) == 0 {
+		schemes = defaultURLSchemes
+	}
+	if !containsFold(schemes, u.Scheme) {
+		return NewValidationError(
+			CodeURLScheme,
+			"URL scheme {scheme} is not allowed (allowed: {allowed})",
+			map[string]interface{}{"scheme": u.Scheme, "allowed": strings.Join(schemes, ", ")},
+		)
+	}
+
+	if u.Opaque != "" {
+		// Opaque URL such as "http:example.com" has no host to check.
+		if containsFold(hostURLSchemes, u.Scheme) || len(f.AllowedHosts) > 0 || len(f.DeniedHosts) > 0 {
+			return errInvalidURL
+		}
+	} else {
+		if u.Host == "" {
+			return errInvalidURL
+		}
+		host := strings.TrimSuffix(mapDomain(u.Hostname()), ".")
+		if host == "" {
+			return errInvalidURL
+		}
+		// Browsers may map non-ASCII host to a listed one in ways that
+		// mapDomain does not cover, so such hosts are not matched at all.
+		nonASCII := !isASCII(host) && (len(f.AllowedHosts) > 0 || len(f.DeniedHosts) > 0)
+		if f.IDNA {
+			if host, err = domainToASCII(host); err != nil {
+				return errInvalidURL
+			}
+		}
+		if nonASCII || len(f.AllowedHosts) > 0 && !matchHost(f.AllowedHosts, host) || matchHost(f.DeniedHosts, host) {
+			return NewValidationError(
+				CodeURLHost,
+				"URL host {host} is not allowed",
+				map[string]interface{}{"host": host},
+			)
+		}
+		if port := u.Port(); port != "" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		u.Host = host
+	}
+
+	return f.StringField.Validate(u.String())
+}
+
+// matchHost reports whether host matches one of hosts (see URLField).
+func matchHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		h = strings.TrimSuffix(mapDomain(h), ".")
+		if host == h || host == strings.TrimPrefix(h, ".") {
+			return true
+		}
+		if strings.HasPrefix(h, ".") && strings.HasSuffix(host, h) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *URLField) Render(attrs ...string) template.HTML {
+	constraints := f.html5Constraints()
+	if !f.AllowRelative {
+		constraints = append(f.inputType("url"), constraints...)
+	}
+	return f.Widget().Render(f.withHTML5Attrs(constraints, attrs), f.StringValue())
+}
+
+func NewURLField() *URLField {
+	return &URLField{
+		StringField: NewStringField(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
 type StringChoice struct {
 	Value string
 	Label string
@@ -303,7 +758,7 @@ func (f *StringChoiceField) SetChoices(choices []StringChoice) {
 	}
 
 	f.Widget().(ChoiceWidget).SetChoices(strChoices)
-	f.AddValidator(NewStringChoicesValidator(choices))
+	f.replaceValidator(NewStringChoicesValidator(choices))
 }
 
 func NewSelectStringField() *StringChoiceField {
@@ -330,6 +785,18 @@ func NewRadioStringField() *StringChoiceField {
 
 type Int64Field struct {
 	*BaseField
+	min, max       int64
+	hasMin, hasMax bool
+}
+
+func (f *Int64Field) SetMin(min int64) {
+	f.min = min
+	f.hasMin = true
+}
+
+func (f *Int64Field) SetMax(max int64) {
+	f.max = max
+	f.hasMax = true
 }
 
 func (f *Int64Field) Value() int64 {
@@ -342,11 +809,19 @@ func (f *Int64Field) Value() int64 {
 func (f *Int64Field) Validate(rawValue interface{}) error {
 	value, err := strconv.ParseInt(fmt.Sprint(rawValue), 10, 64)
 	if err != nil {
-		return err
+		return errNotInteger
 	}
 
-	if err := f.ApplyValidators(value); err != nil {
-		return err
+	var errs ValidationErrors
+	if f.hasMin && value < f.min {
+		errs.Add(errMinValue(f.min))
+	}
+	if f.hasMax && value > f.max {
+		errs.Add(errMaxValue(f.max))
+	}
+	errs.Add(f.ApplyValidators(value))
+	if len(errs) > 0 {
+		return errs
 	}
 
 	f.iValue = value
@@ -357,8 +832,19 @@ func (f *Int64Field) SetInitial(initial int64) {
 	f.iValue = initial
 }
 
+func (f *Int64Field) html5Constraints() []string {
+	constraints := f.inputType("number")
+	if f.hasMin {
+		constraints = append(constraints, "min", strconv.FormatInt(f.min, 10))
+	}
+	if f.hasMax {
+		constraints = append(constraints, "max", strconv.FormatInt(f.max, 10))
+	}
+	return constraints
+}
+
 func (f *Int64Field) Render(attrs ...string) template.HTML {
-	return f.Widget().Render(attrs, f.StringValue())
+	return f.Widget().Render(f.withHTML5Attrs(f.html5Constraints(), attrs), f.StringValue())
 }
 
 func NewInt64Field() *Int64Field {
@@ -388,7 +874,7 @@ func (f *Int64ChoiceField) SetChoices(choices []Int64Choice) {
 	}
 
 	f.Widget().(ChoiceWidget).SetChoices(strChoices)
-	f.AddValidator(NewInt64ChoicesValidator(choices))
+	f.replaceValidator(NewInt64ChoicesValidator(choices))
 }
 
 func NewSelectInt64Field() *Int64ChoiceField {
@@ -413,6 +899,491 @@ func NewRadioInt64Field() *Int64ChoiceField {
 
 //------------------------------------------------------------------------------
 
+type Float64Field struct {
+	*BaseField
+	min, max       float64
+	hasMin, hasMax bool
+	step           float64
+}
+
+func (f *Float64Field) SetMin(min float64) {
+	f.min = min
+	f.hasMin = true
+}
+
+func (f *Float64Field) SetMax(max float64) {
+	f.max = max
+	f.hasMax = true
+}
+
+// SetStep sets granularity of the value. Value should be equal to
+// min (or 0 when min is not set) plus integer number of steps.
+func (f *Float64Field) SetStep(step float64) {
+	f.step = step
+}
+
+func (f *Float64Field) Value() float64 {
+	if f.iValue == nil {
+		return 0
+	}
+	return f.iValue.(float64)
+}
+
+func (f *Float64Field) Validate(rawValue interface{}) error {
+	value, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(rawValue)), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return errNotNumber
+	}
+
+	var errs ValidationErrors
+	if f.hasMin && value < f.min {
+		errs.Add(errMinValue(f.min))
+	}
+	if f.hasMax && value > f.max {
+		errs.Add(errMaxValue(f.max))
+	}
+	if f.step > 0 {
+		steps := (value - f.min) / f.step
+		if math.Abs(steps-math.Round(steps)) > 1e-9 {
+			errs.Add(errStep(f.step))
+		}
+	}
+	errs.Add(f.ApplyValidators(value))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	f.iValue = value
+	return nil
+}
+
+func (f *Float64Field) SetInitial(initial float64) {
+	f.iValue = initial
+}
+
+func (f *Float64Field) StringValue() string {
+	if f.iValue == nil {
+		return ""
+	}
+	return strconv.FormatFloat(f.Value(), 'f', -1, 64)
+}
+
+func (f *Float64Field) html5Constraints() []string {
+	attrs := f.inputType("number")
+	if f.hasMin {
+		attrs = append(attrs, "min", strconv.FormatFloat(f.min, 'f', -1, 64))
+	}
+	if f.hasMax {
+		attrs = append(attrs, "max", strconv.FormatFloat(f.max, 'f', -1, 64))
+	}
+	if f.step > 0 {
+		attrs = append(attrs, "step", strconv.FormatFloat(f.step, 'f', -1, 64))
+	} else {
+		attrs = append(attrs, "step", "any")
+	}
+	return attrs
+}
+
+func (f *Float64Field) Render(attrs ...string) template.HTML {
+	return f.Widget().Render(f.withHTML5Attrs(f.html5Constraints(), attrs), f.StringValue())
+}
+
+func NewFloat64Field() *Float64Field {
+	return &Float64Field{
+		BaseField: &BaseField{
+			widget: NewNumberWidget(),
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+var decimalRe = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// DecimalField keeps exact decimal value. Submitted string is preserved
+// so the value is rendered back exactly as it was entered.
+type DecimalField struct {
+	*BaseField
+	// MaxDigits is maximum number of digits (before and after the point).
+	MaxDigits int
+	// DecimalPlaces is maximum number of digits after the point.
+	DecimalPlaces int
+
+	min, max, step *big.Rat
+	str            string
+}
+
+func (f *DecimalField) SetMin(min *big.Rat) {
+	f.min = min
+}
+
+func (f *DecimalField) SetMax(max *big.Rat) {
+	f.max = max
+}
+
+// SetStep sets granularity of the value. Value should be equal to
+// min (or 0 when min is not set) plus integer number of steps.
+func (f *DecimalField) SetStep(step *big.Rat) {
+	f.step = step
+}
+
+func (f *DecimalField) Value() *big.Rat {
+	if f.iValue == nil {
+		return nil
+	}
+	return f.iValue.(*big.Rat)
+}
+
+func (f *DecimalField) Validate(rawValue interface{}) error {
+	str := strings.TrimSpace(fmt.Sprint(rawValue))
+	if !decimalRe.MatchString(str) {
+		return errNotNumber
+	}
+	value, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return errNotNumber
+	}
+
+	var errs ValidationErrors
+	intPart, fracPart := splitDecimal(str)
+	if f.MaxDigits > 0 && len(intPart)+len(fracPart) > f.MaxDigits {
+		errs.Add(NewValidationError(
+			CodeMaxDigits,
+			"This field should have no more than {max} digits in total",
+			map[string]interface{}{"max": f.MaxDigits},
+		))
+	}
+	if f.DecimalPlaces > 0 && len(fracPart) > f.DecimalPlaces {
+		errs.Add(NewValidationError(
+			CodeMaxDecimalPlaces,
+			"This field should have no more than {max} decimal places",
+			map[string]interface{}{"max": f.DecimalPlaces},
+		))
+	}
+
+	if f.min != nil && value.Cmp(f.min) < 0 {
+		errs.Add(errMinValue(formatDecimal(f.min)))
+	}
+	if f.max != nil && value.Cmp(f.max) > 0 {
+		errs.Add(errMaxValue(formatDecimal(f.max)))
+	}
+	if f.step != nil && f.step.Sign() > 0 {
+		steps := new(big.Rat).Set(value)
+		if f.min != nil {
+			steps.Sub(steps, f.min)
+		}
+		if !steps.Quo(steps, f.step).IsInt() {
+			errs.Add(errStep(formatDecimal(f.step)))
+		}
+	}
+	errs.Add(f.ApplyValidators(value))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	f.iValue = value
+	f.str = str
+	return nil
+}
+
+func (f *DecimalField) SetInitial(initial *big.Rat) {
+	f.iValue = initial
+	f.str = ""
+}
+
+func (f *DecimalField) StringValue() string {
+	if f.iValue == nil {
+		return ""
+	}
+	if f.str != "" {
+		return f.str
+	}
+	return formatDecimal(f.Value())
+}
+
+func (f *DecimalField) Reset() {
+	f.BaseField.Reset()
+	f.str = ""
+}
+
+func (f *DecimalField) html5Constraints() []string {
+	attrs := f.inputType("number")
+	if f.min != nil {
+		attrs = append(attrs, "min", formatDecimal(f.min))
+	}
+	if f.max != nil {
+		attrs = append(attrs, "max", formatDecimal(f.max))
+	}
+	switch {
+	case f.step != nil && f.step.Sign() > 0:
+		attrs = append(attrs, "step", formatDecimal(f.step))
+	case f.DecimalPlaces > 0:
+		attrs = append(attrs, "step", formatDecimal(new(big.Rat).SetFrac(
+			big.NewInt(1),
+			new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(f.DecimalPlaces)), nil),
+		)))
+	default:
+		attrs = append(attrs, "step", "any")
+	}
+	return attrs
+}
+
+func (f *DecimalField) Render(attrs ...string) template.HTML {
+	return f.Widget().Render(f.withHTML5Attrs(f.html5Constraints(), attrs), f.StringValue())
+}
+
+func NewDecimalField() *DecimalField {
+	return &DecimalField{
+		BaseField: &BaseField{
+			widget: NewNumberWidget(),
+		},
+	}
+}
+
+// splitDecimal returns significant digits before and after the point.
+func splitDecimal(s string) (string, string) {
+	s = strings.TrimLeft(s, "+-")
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	return strings.TrimLeft(intPart, "0"), fracPart
+}
+
+// formatDecimal formats r without exponent. Values that have no finite
+// decimal representation are rounded to 20 decimal places.
+func formatDecimal(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	places := 0
+	denom := new(big.Int).Set(r.Denom())
+	for _, p := range []int64{2, 5} {
+		n := 0
+		m := new(big.Int)
+		for {
+			q, rem := new(big.Int).QuoRem(denom, big.NewInt(p), m)
+			if rem.Sign() != 0 {
+				break
+			}
+			denom = q
+			n++
+		}
+		if n > places {
+			places = n
+		}
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		places = 20
+	}
+	return r.FloatString(places)
+}
+
+//------------------------------------------------------------------------------
+
+const (
+	HTML5DateLayout          = "2006-01-02"
+	HTML5TimeLayout          = "15:04"
+	HTML5DateTimeLocalLayout = "2006-01-02T15:04"
+
+	html5TimeSecondsLayout          = "15:04:05"
+	html5DateTimeLocalSecondsLayout = "2006-01-02T15:04:05"
+)
+
+// html5SecondsLayouts maps HTML5 layouts to layouts with seconds, which
+// are used for values with non-zero seconds, so they are not truncated on
+// redisplay. Custom layouts are always used as is.
+var html5SecondsLayouts = map[string]string{
+	HTML5TimeLayout:          html5TimeSecondsLayout,
+	HTML5DateTimeLocalLayout: html5DateTimeLocalSecondsLayout,
+}
+
+// DateTimeField parses submitted value using Layouts. Values without time
+// zone offset are interpreted in Location (UTC when nil). Value is
+// rendered using Layout, which should match format expected by widget.
+type DateTimeField struct {
+	*BaseField
+	Layouts  []string
+	Layout   string
+	Location *time.Location
+
+	min, max  time.Time
+	normalize func(time.Time, *time.Location) time.Time
+}
+
+// SetMin sets earliest allowed value. For DateField only date is compared
+// and for TimeField only time of day.
+func (f *DateTimeField) SetMin(min time.Time) {
+	f.min = min
+}
+
+// SetMax sets latest allowed value. For DateField only date is compared
+// and for TimeField only time of day.
+func (f *DateTimeField) SetMax(max time.Time) {
+	f.max = max
+}
+
+func (f *DateTimeField) location() *time.Location {
+	if f.Location == nil {
+		return time.UTC
+	}
+	return f.Location
+}
+
+func (f *DateTimeField) norm(t time.Time) time.Time {
+	if f.normalize == nil {
+		return t
+	}
+	return f.normalize(t, f.location())
+}
+
+func (f *DateTimeField) format(t time.Time) string {
+	t = t.In(f.location())
+	if f.hasSeconds(t) {
+		return t.Format(html5SecondsLayouts[f.Layout])
+	}
+	return t.Format(f.Layout)
+}
+
+// hasSeconds reports whether t should be rendered with seconds.
+func (f *DateTimeField) hasSeconds(t time.Time) bool {
+	_, ok := html5SecondsLayouts[f.Layout]
+	return ok && t.Second() != 0
+}
+
+func (f *DateTimeField) parse(str string) (time.Time, bool) {
+	str = strings.TrimSpace(str)
+	for _, layout := range f.Layouts {
+		t, err := time.ParseInLocation(layout, str, f.location())
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (f *DateTimeField) Value() time.Time {
+	if f.iValue == nil {
+		return time.Time{}
+	}
+	return f.iValue.(time.Time)
+}
+
+func (f *DateTimeField) Validate(rawValue interface{}) error {
+	value, ok := f.parse(fmt.Sprint(rawValue))
+	if !ok {
+		return NewValidationError(CodeInvalidDateTime, "This field should be a valid date/time", nil)
+	}
+
+	var errs ValidationErrors
+	if !f.min.IsZero() && f.norm(value).Before(f.norm(f.min)) {
+		errs.Add(NewValidationError(
+			CodeMinDateTime,
+			"This field should not be earlier than {min}",
+			map[string]interface{}{"min": f.format(f.min)},
+		))
+	}
+	if !f.max.IsZero() && f.norm(value).After(f.norm(f.max)) {
+		errs.Add(NewValidationError(
+			CodeMaxDateTime,
+			"This field should not be later than {max}",
+			map[string]interface{}{"max": f.format(f.max)},
+		))
+	}
+	errs.Add(f.ApplyValidators(value))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	f.iValue = value
+	return nil
+}
+
+func (f *DateTimeField) SetInitial(initial time.Time) {
+	f.iValue = initial
+}
+
+func (f *DateTimeField) StringValue() string {
+	if f.iValue == nil {
+		return ""
+	}
+	return f.format(f.Value())
+}
+
+func (f *DateTimeField) Render(attrs ...string) template.HTML {
+	constraints := make([]string, 0, 4)
+	if !f.min.IsZero() {
+		constraints = append(constraints, "min", f.format(f.min))
+	}
+	if !f.max.IsZero() {
+		constraints = append(constraints, "max", f.format(f.max))
+	}
+	// Browsers reject seconds unless step allows them.
+	if f.hasSeconds(f.Value()) || f.hasSeconds(f.min) || f.hasSeconds(f.max) {
+		constraints = append(constraints, "step", "1")
+	}
+	return f.Widget().Render(f.withHTML5Attrs(constraints, attrs), f.StringValue())
+}
+
+func NewDateTimeField() *DateTimeField {
+	return &DateTimeField{
+		BaseField: &BaseField{
+			widget: NewDateTimeWidget(),
+		},
+		Layouts: []string{
+			HTML5DateTimeLocalLayout,
+			"2006-01-02T15:04:05",
+			time.RFC3339,
+			"2006-01-02 15:04:05",
+			"2006-01-02 15:04",
+		},
+		Layout: HTML5DateTimeLocalLayout,
+	}
+}
+
+type DateField struct {
+	*DateTimeField
+}
+
+func NewDateField() *DateField {
+	return &DateField{
+		DateTimeField: &DateTimeField{
+			BaseField: &BaseField{
+				widget: NewDateWidget(),
+			},
+			Layouts: []string{HTML5DateLayout},
+			Layout:  HTML5DateLayout,
+			normalize: func(t time.Time, loc *time.Location) time.Time {
+				y, m, d := t.In(loc).Date()
+				return time.Date(y, m, d, 0, 0, 0, 0, loc)
+			},
+		},
+	}
+}
+
+type TimeField struct {
+	*DateTimeField
+}
+
+func NewTimeField() *TimeField {
+	return &TimeField{
+		DateTimeField: &DateTimeField{
+			BaseField: &BaseField{
+				widget: NewTimeWidget(),
+			},
+			Layouts: []string{HTML5TimeLayout, html5TimeSecondsLayout},
+			Layout:  HTML5TimeLayout,
+			normalize: func(t time.Time, loc *time.Location) time.Time {
+				h, m, s := t.In(loc).Clock()
+				return time.Date(0, 1, 1, h, m, s, t.Nanosecond(), loc)
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
 type BoolField struct {
 	*BaseField
 }
@@ -440,6 +1411,7 @@ func (f *BoolField) SetInitial(initial bool) {
 }
 
 func (f *BoolField) Render(attrs ...string) template.HTML {
+	attrs = f.withHTML5Attrs(nil, attrs)
 	if f.StringValue() == "true" {
 		attrs = append(attrs, "checked", "checked")
 	}
@@ -467,21 +1439,34 @@ func (f *MultiStringChoiceField) Value() []string {
 	return f.iValue.([]string)
 }
 
-func (f *MultiStringChoiceField) Validate(rawValue interface{}) error {
-	valuesI, ok := rawValue.([]interface{})
-	if !ok {
-		return fmt.Errorf("Type %T is not supported", rawValue)
+// multiValues returns raw values of multi value field: []string from
+// url.Values or []interface{} (e.g. decoded from JSON).
+func multiValues(rawValue interface{}) ([]string, bool) {
+	switch values := rawValue.(type) {
+	case []string:
+		return values, true
+	case []interface{}:
+		strs := make([]string, 0, len(values))
+		for _, value := range values {
+			strs = append(strs, fmt.Sprint(value))
+		}
+		return strs, true
 	}
+	return nil, false
+}
 
-	values := make([]string, 0)
-	for _, valueI := range valuesI {
-		values = append(values, fmt.Sprint(valueI))
+func (f *MultiStringChoiceField) Validate(rawValue interface{}) error {
+	values, ok := multiValues(rawValue)
+	if !ok {
+		return errUnsupportedType(rawValue)
 	}
 
+	var errs ValidationErrors
 	for _, value := range values {
-		if err := f.ApplyValidators(value); err != nil {
-			return err
-		}
+		errs.Add(f.ApplyValidators(value))
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 
 	f.iValue = values
@@ -497,7 +1482,7 @@ func (f *MultiStringChoiceField) StringValue() []string {
 }
 
 func (f *MultiStringChoiceField) Render(attrs ...string) template.HTML {
-	return f.Widget().Render(attrs, f.StringValue()...)
+	return f.Widget().Render(f.withHTML5Attrs(nil, attrs), f.StringValue()...)
 }
 
 func NewMultiSelectStringField() *MultiStringChoiceField {
@@ -513,6 +1498,19 @@ func NewMultiSelectStringField() *MultiStringChoiceField {
 	}
 }
 
+func NewCheckboxGroupStringField() *MultiStringChoiceField {
+	return &MultiStringChoiceField{
+		StringChoiceField: &StringChoiceField{
+			StringField: &StringField{
+				BaseField: &BaseField{
+					widget:  NewCheckboxGroupWidget(),
+					isMulti: true,
+				},
+			},
+		},
+	}
+}
+
 //------------------------------------------------------------------------------
 
 type MultiInt64ChoiceField struct {
@@ -527,24 +1525,26 @@ func (f *MultiInt64ChoiceField) Value() []int64 {
 }
 
 func (f *MultiInt64ChoiceField) Validate(rawValue interface{}) error {
-	valuesI, ok := rawValue.([]interface{})
+	strs, ok := multiValues(rawValue)
 	if !ok {
-		return fmt.Errorf("Type %T is not supported", rawValue)
+		return errUnsupportedType(rawValue)
 	}
 
 	values := make([]int64, 0)
-	for _, valueI := range valuesI {
-		value, err := strconv.ParseInt(fmt.Sprint(valueI), 10, 64)
+	for _, str := range strs {
+		value, err := strconv.ParseInt(str, 10, 64)
 		if err != nil {
-			return err
+			return errNotInteger
 		}
 		values = append(values, value)
 	}
 
+	var errs ValidationErrors
 	for _, value := range values {
-		if err := f.ApplyValidators(value); err != nil {
-			return err
-		}
+		errs.Add(f.ApplyValidators(value))
+	}
+	if len(errs) > 0 {
+		return errs
 	}
 
 	f.iValue = values
@@ -564,7 +1564,7 @@ func (f *MultiInt64ChoiceField) StringValue() []string {
 }
 
 func (f *MultiInt64ChoiceField) Render(attrs ...string) template.HTML {
-	return f.Widget().Render(attrs, f.StringValue()...)
+	return f.Widget().Render(f.withHTML5Attrs(nil, attrs), f.StringValue()...)
 }
 
 func NewMultiSelectInt64Field() *MultiInt64ChoiceField {
@@ -580,6 +1580,19 @@ func NewMultiSelectInt64Field() *MultiInt64ChoiceField {
 	}
 }
 
+func NewCheckboxGroupInt64Field() *MultiInt64ChoiceField {
+	return &MultiInt64ChoiceField{
+		Int64ChoiceField: &Int64ChoiceField{
+			Int64Field: &Int64Field{
+				BaseField: &BaseField{
+					widget:  NewCheckboxGroupWidget(),
+					isMulti: true,
+				},
+			},
+		},
+	}
+}
+
 //------------------------------------------------------------------------------
 
 type FileField struct {
@@ -596,7 +1609,7 @@ func (f *FileField) Value() *multipart.FileHeader {
 func (f *FileField) Validate(rawValue interface{}) error {
 	value, ok := rawValue.(*multipart.FileHeader)
 	if !ok {
-		return fmt.Errorf("Type %T is not supported", rawValue)
+		return errUnsupportedType(rawValue)
 	}
 
 	if err := f.ApplyValidators(value); err != nil {
@@ -611,8 +1624,30 @@ func (f *FileField) SetInitial(initial *multipart.FileHeader) {
 	f.iValue = initial
 }
 
+func (f *FileField) html5Constraints() []string {
+	return fileAccept(f.validators)
+}
+
+// fileAccept returns accept attribute built from file extension and type
+// validators.
+func fileAccept(validators []Validator) []string {
+	var accept []string
+	for _, validator := range validators {
+		switch v := validator.(type) {
+		case *FileExtensionValidator:
+			accept = append(accept, v.accept()...)
+		case *FileTypeValidator:
+			accept = append(accept, v.Types...)
+		}
+	}
+	if len(accept) == 0 {
+		return nil
+	}
+	return []string{"accept", strings.Join(accept, ",")}
+}
+
 func (f *FileField) Render(attrs ...string) template.HTML {
-	return f.widget.Render(attrs)
+	return f.widget.Render(f.withHTML5Attrs(f.html5Constraints(), attrs))
 }
 
 func NewFileField() *FileField {
@@ -623,3 +1658,225 @@ func NewFileField() *FileField {
 		},
 	}
 }
+
+//------------------------------------------------------------------------------
+
+// MultiFileField accepts several files uploaded with one
+// <input type="file" multiple>. Validators are applied to every file and
+// their errors are wrapped in FileError with the file name and index.
+type MultiFileField struct {
+	*BaseField
+	// MinFiles and MaxFiles limit number of uploaded files; zero means
+	// no limit. Use SetIsRequired to require at least one file.
+	MinFiles, MaxFiles int
+}
+
+func (f *MultiFileField) Value() []*multipart.FileHeader {
+	if f.iValue == nil {
+		return nil
+	}
+	return f.iValue.([]*multipart.FileHeader)
+}
+
+func (f *MultiFileField) Validate(rawValue interface{}) error {
+	values, ok := rawValue.([]*multipart.FileHeader)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+
+	if f.MinFiles > 0 && len(values) < f.MinFiles {
+		return NewValidationError(
+			CodeMinFiles,
+			"Upload at least {min} files",
+			map[string]interface{}{"min": f.MinFiles, "count": len(values)},
+		)
+	}
+	if f.MaxFiles > 0 && len(values) > f.MaxFiles {
+		return NewValidationError(
+			CodeMaxFiles,
+			"Upload at most {max} files",
+			map[string]interface{}{"max": f.MaxFiles, "count": len(values)},
+		)
+	}
+
+	var errs ValidationErrors
+	for i, value := range values {
+		for _, err := range ErrorList(f.ApplyValidators(value)) {
+			errs.Add(&FileError{Index: i, Filename: value.Filename, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	f.iValue = values
+	return nil
+}
+
+func (f *MultiFileField) SetInitial(initial []*multipart.FileHeader) {
+	f.iValue = initial
+}
+
+func (f *MultiFileField) Render(attrs ...string) template.HTML {
+	return f.widget.Render(f.withHTML5Attrs(fileAccept(f.validators), attrs))
+}
+
+func NewMultiFileField() *MultiFileField {
+	return &MultiFileField{
+		BaseField: &BaseField{
+			widget:      NewMultiFileWidget(),
+			isMulti:     true,
+			isMultipart: true,
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// ImageField is FileField that accepts images. Only image header is
+// decoded (image.DecodeConfig), so large uploads are rejected without
+// loading them into memory. PNG, JPEG and GIF are supported.
+type ImageField struct {
+	*FileField
+	// Dimension limits in pixels; zero means no limit.
+	MinWidth, MaxWidth   int
+	MinHeight, MaxHeight int
+	// Formats lists allowed formats as named by image package, e.g.
+	// "png" or "jpeg". Empty means any supported format.
+	Formats []string
+	// AspectRatioTolerance is allowed relative difference from aspect
+	// ratio set with SetAspectRatio. Default is 0.01.
+	AspectRatioTolerance float64
+
+	ratioW, ratioH int
+	config         image.Config
+	format         string
+}
+
+// SetAspectRatio requires width:height of the image to be w:h.
+func (f *ImageField) SetAspectRatio(w, h int) {
+	f.ratioW, f.ratioH = w, h
+}
+
+// Config returns dimensions and color model of the validated image.
+func (f *ImageField) Config() image.Config {
+	return f.config
+}
+
+// Format returns format of the validated image, e.g. "png".
+func (f *ImageField) Format() string {
+	return f.format
+}
+
+func (f *ImageField) Reset() {
+	f.FileField.Reset()
+	f.config = image.Config{}
+	f.format = ""
+}
+
+func (f *ImageField) Validate(rawValue interface{}) error {
+	value, ok := rawValue.(*multipart.FileHeader)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+
+	file, err := value.Open()
+	if err != nil {
+		return errReadFile
+	}
+	config, format, err := image.DecodeConfig(file)
+	file.Close()
+	if err != nil {
+		return errInvalidImage
+	}
+
+	var errs ValidationErrors
+	if len(f.Formats) > 0 && !containsFold(f.Formats, format) {
+		errs.Add(NewValidationError(
+			CodeImageFormat,
+			"Image format {format} is not allowed (allowed: {allowed})",
+			map[string]interface{}{"format": format, "allowed": strings.Join(f.Formats, ", ")},
+		))
+	}
+	errs.Add(checkImageSize(CodeMinImageWidth, "Image width should be at least {min}px", f.MinWidth, config.Width, -1))
+	errs.Add(checkImageSize(CodeMaxImageWidth, "Image width should be at most {max}px", f.MaxWidth, config.Width, 1))
+	errs.Add(checkImageSize(CodeMinImageHeight, "Image height should be at least {min}px", f.MinHeight, config.Height, -1))
+	errs.Add(checkImageSize(CodeMaxImageHeight, "Image height should be at most {max}px", f.MaxHeight, config.Height, 1))
+	if f.ratioW > 0 && f.ratioH > 0 && !f.hasAspectRatio(config) {
+		errs.Add(NewValidationError(
+			CodeAspectRatio,
+			"Image aspect ratio should be {ratio}",
+			map[string]interface{}{
+				"ratio":  fmt.Sprintf("%d:%d", f.ratioW, f.ratioH),
+				"width":  config.Width,
+				"height": config.Height,
+			},
+		))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if err := f.FileField.Validate(value); err != nil {
+		return err
+	}
+	f.config = config
+	f.format = format
+	return nil
+}
+
+func (f *ImageField) hasAspectRatio(config image.Config) bool {
+	if config.Height == 0 {
+		return false
+	}
+	tolerance := f.AspectRatioTolerance
+	if tolerance == 0 {
+		tolerance = 0.01
+	}
+	want := float64(f.ratioW) / float64(f.ratioH)
+	got := float64(config.Width) / float64(config.Height)
+	return math.Abs(got-want)/want <= tolerance
+}
+
+// checkImageSize returns error when limit is set and size is less
+// (sign -1) or greater (sign 1) than limit.
+func checkImageSize(code, message string, limit, size, sign int) error {
+	if limit <= 0 || (size-limit)*sign <= 0 {
+		return nil
+	}
+	param := "min"
+	if sign > 0 {
+		param = "max"
+	}
+	return NewValidationError(code, message, map[string]interface{}{param: limit, "size": size})
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ImageField) Render(attrs ...string) template.HTML {
+	constraints := f.html5Constraints()
+	if constraints == nil {
+		accept := []string{"image/*"}
+		if len(f.Formats) > 0 {
+			accept = accept[:0]
+			for _, format := range f.Formats {
+				accept = append(accept, "image/"+strings.ToLower(format))
+			}
+		}
+		constraints = []string{"accept", strings.Join(accept, ",")}
+	}
+	return f.widget.Render(f.withHTML5Attrs(constraints, attrs))
+}
+
+func NewImageField() *ImageField {
+	return &ImageField{
+		FileField: NewFileField(),
+	}
+}
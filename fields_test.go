@@ -1,7 +1,15 @@
 package gforms_test
 
 import (
+	"bytes"
 	"html/template"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"math/big"
+	"mime/multipart"
+	"time"
 
 	. "launchpad.net/gocheck"
 
@@ -19,7 +27,7 @@ func (t *FieldsTest) TestRequiredStringFieldDoNotPassValidation(c *C) {
 	c.Assert(gforms.IsFieldValid(f, nil), Equals, false)
 	c.Assert(f.ValidationError().Error(), Equals, "This field is required")
 	c.Assert(f.Value(), Equals, "")
-	c.Assert(f.Render(), Equals, template.HTML(`<input type="text" value="" />`))
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="text" required="required" value="" />`))
 }
 
 func (t *FieldsTest) TestRequiredStringFieldPassValidation(c *C) {
@@ -120,3 +128,493 @@ func (t *FieldsTest) TestMultiSelectInt64FieldValidation(c *C) {
 	c.Assert(f.ValidationError(), IsNil)
 	c.Assert(f.Value(), DeepEquals, []int64{1, 2})
 }
+
+//------------------------------------------------------------------------------
+
+func (t *FieldsTest) TestRadioStringFieldRender(c *C) {
+	f := gforms.NewRadioStringField()
+	f.SetName("lang")
+	f.SetChoices([]gforms.StringChoice{{"go", "Golang"}, {"py", "Python"}})
+	f.SetInitial("py")
+
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<label for="lang_0"><input type="radio" id="lang_0" name="lang" value="go" /> Golang</label>`+"\n"+
+			`<label for="lang_1"><input type="radio" id="lang_1" name="lang" value="py" checked="checked" /> Python</label>`,
+	))
+}
+
+func (t *FieldsTest) TestCheckboxGroupInt64FieldValidation(c *C) {
+	f := gforms.NewCheckboxGroupInt64Field()
+	f.SetName("ids")
+	f.SetChoices([]gforms.Int64Choice{{1, "foo"}, {2, "bar"}})
+
+	c.Assert(gforms.IsFieldValid(f, []interface{}{3}), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "3 is invalid choice")
+
+	c.Assert(gforms.IsFieldValid(f, []interface{}{2}), Equals, true)
+	c.Assert(f.Value(), DeepEquals, []int64{2})
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<label for="ids_0"><input type="checkbox" id="ids_0" name="ids" value="1" /> foo</label>`+"\n"+
+			`<label for="ids_1"><input type="checkbox" id="ids_1" name="ids" value="2" checked="checked" /> bar</label>`,
+	))
+}
+
+//------------------------------------------------------------------------------
+
+func (t *FieldsTest) TestFloat64FieldValidation(c *C) {
+	f := gforms.NewFloat64Field()
+	f.SetMin(0)
+	f.SetMax(10)
+	f.SetStep(0.5)
+
+	c.Assert(gforms.IsFieldValid(f, "x"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be a number")
+
+	c.Assert(gforms.IsFieldValid(f, "NaN"), Equals, false)
+
+	c.Assert(gforms.IsFieldValid(f, "-1"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be greater than or equal to 0")
+
+	c.Assert(gforms.IsFieldValid(f, "10.5"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be less than or equal to 10")
+
+	c.Assert(gforms.IsFieldValid(f, "1.2"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be a multiple of 0.5")
+
+	c.Assert(gforms.IsFieldValid(f, "2.5"), Equals, true)
+	c.Assert(f.Value(), Equals, 2.5)
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<input type="number" min="0" max="10" step="0.5" value="2.5" />`,
+	))
+}
+
+func (t *FieldsTest) TestFloat64FieldRenderAnyStep(c *C) {
+	f := gforms.NewFloat64Field()
+	f.SetInitial(1000000)
+
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="number" step="any" value="1000000" />`))
+}
+
+func (t *FieldsTest) TestDecimalFieldValidation(c *C) {
+	f := gforms.NewDecimalField()
+	f.MaxDigits = 5
+	f.DecimalPlaces = 2
+	f.SetMin(big.NewRat(1, 100))
+
+	c.Assert(gforms.IsFieldValid(f, "1e3"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be a number")
+
+	c.Assert(gforms.IsFieldValid(f, "12345.6"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should have no more than 5 digits in total")
+
+	c.Assert(gforms.IsFieldValid(f, "1.234"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should have no more than 2 decimal places")
+
+	c.Assert(gforms.IsFieldValid(f, "0.00"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be greater than or equal to 0.01")
+
+	c.Assert(gforms.IsFieldValid(f, "019.90"), Equals, true)
+	c.Assert(f.Value().Cmp(big.NewRat(199, 10)), Equals, 0)
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<input type="number" min="0.01" step="0.01" value="019.90" />`,
+	))
+}
+
+func (t *FieldsTest) TestDecimalFieldStep(c *C) {
+	f := gforms.NewDecimalField()
+	f.SetStep(big.NewRat(1, 4))
+
+	c.Assert(gforms.IsFieldValid(f, "0.3"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be a multiple of 0.25")
+
+	c.Assert(gforms.IsFieldValid(f, "0.75"), Equals, true)
+
+	f.SetInitial(big.NewRat(1, 8))
+	c.Assert(f.StringValue(), Equals, "0.125")
+}
+
+//------------------------------------------------------------------------------
+
+func (t *FieldsTest) TestDateFieldValidation(c *C) {
+	f := gforms.NewDateField()
+	f.SetMin(time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	c.Assert(gforms.IsFieldValid(f, "2014-13-01"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should be a valid date/time")
+
+	c.Assert(gforms.IsFieldValid(f, "2013-12-31"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should not be earlier than 2014-01-01")
+
+	c.Assert(gforms.IsFieldValid(f, "2014-02-03"), Equals, true)
+	c.Assert(f.Value(), Equals, time.Date(2014, 2, 3, 0, 0, 0, 0, time.UTC))
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<input type="date" min="2014-01-01" value="2014-02-03" />`,
+	))
+}
+
+func (t *FieldsTest) TestTimeFieldValidation(c *C) {
+	f := gforms.NewTimeField()
+	f.SetMax(time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC))
+
+	c.Assert(gforms.IsFieldValid(f, "18:30"), Equals, false)
+	c.Assert(f.ValidationError().Error(), Equals, "This field should not be later than 18:00")
+
+	c.Assert(gforms.IsFieldValid(f, "09:15:30"), Equals, true)
+	c.Assert(f.Value().Hour(), Equals, 9)
+	c.Assert(f.Value().Second(), Equals, 30)
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<input type="time" max="18:00" step="1" value="09:15:30" />`,
+	))
+	c.Assert(gforms.IsFieldValid(f, f.StringValue()), Equals, true)
+	c.Assert(f.Value().Second(), Equals, 30)
+
+	c.Assert(gforms.IsFieldValid(f, "09:15"), Equals, true)
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="time" max="18:00" value="09:15" />`))
+}
+
+func (t *FieldsTest) TestDateTimeFieldLocation(c *C) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	f := gforms.NewDateTimeField()
+	f.Location = loc
+
+	c.Assert(gforms.IsFieldValid(f, "2014-05-06T10:30"), Equals, true)
+	c.Assert(f.Value().Equal(time.Date(2014, 5, 6, 7, 30, 0, 0, time.UTC)), Equals, true)
+
+	c.Assert(gforms.IsFieldValid(f, "2014-05-06T10:30:00Z"), Equals, true)
+	c.Assert(f.Value().Equal(time.Date(2014, 5, 6, 10, 30, 0, 0, time.UTC)), Equals, true)
+
+	f.SetInitial(time.Date(2014, 5, 6, 10, 30, 0, 0, time.UTC))
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<input type="datetime-local" value="2014-05-06T13:30" />`,
+	))
+}
+
+func (t *FieldsTest) TestDateTimeFieldCustomLayoutWithSeconds(c *C) {
+	f := gforms.NewDateTimeField()
+	f.Layout = "2006-01-02 15:04"
+	f.SetInitial(time.Date(2014, 5, 6, 10, 30, 15, 0, time.UTC))
+	c.Assert(f.StringValue(), Equals, "2014-05-06 10:30")
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="datetime-local" value="2014-05-06 10:30" />`))
+
+	f.Layout = gforms.HTML5DateTimeLocalLayout
+	c.Assert(f.StringValue(), Equals, "2014-05-06T10:30:15")
+}
+
+//------------------------------------------------------------------------------
+
+func (t *FieldsTest) TestHTML5Attrs(c *C) {
+	f := gforms.NewStringField()
+	f.SetIsRequired(true)
+	f.MaxLen = 10
+	f.AddValidator(gforms.NewPatternValidator("[a-z]+"))
+
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<input type="text" required="required" maxlength="10" pattern="[a-z]+" value="" />`,
+	))
+	c.Assert(f.Render("maxlength", "5"), Equals, template.HTML(
+		`<input type="text" required="required" maxlength="5" pattern="[a-z]+" value="" />`,
+	))
+
+	f.SetHTML5Attrs([]string{"maxlength"})
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="text" maxlength="10" value="" />`))
+
+	f.SetHTML5Attrs(gforms.NoHTML5Attrs)
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="text" value="" />`))
+}
+
+func (t *FieldsTest) TestInt64FieldHTML5Attrs(c *C) {
+	f := gforms.NewInt64Field()
+	f.SetMin(1)
+	f.SetMax(5)
+
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="number" min="1" max="5" value="" />`))
+
+	s := gforms.NewSelectInt64Field()
+	s.SetIsRequired(true)
+	c.Assert(s.Render(), Equals, template.HTML(`<select required="required"></select>`))
+}
+
+func (t *FieldsTest) TestFormHTML5Attrs(c *C) {
+	f := NewPasswordForm()
+	f.Password.SetIsRequired(true)
+	f.SetHTML5Attrs(gforms.NoHTML5Attrs)
+
+	c.Assert(f.Password.Render(), Equals, template.HTML(
+		`<input type="text" id="Password" name="Password" value="" />`,
+	))
+}
+
+//------------------------------------------------------------------------------
+
+func encodePNG(c *C, w, h int) []byte {
+	buf := &bytes.Buffer{}
+	c.Assert(png.Encode(buf, image.NewGray(image.Rect(0, 0, w, h))), IsNil)
+	return buf.Bytes()
+}
+
+func imageErrorCodes(f gforms.Field) []string {
+	codes := make([]string, 0)
+	for _, err := range f.ValidationErrors() {
+		codes = append(codes, err.(*gforms.ValidationError).Code)
+	}
+	return codes
+}
+
+func (t *FieldsTest) TestImageField(c *C) {
+	f := gforms.NewImageField()
+	f.MinWidth = 10
+	f.MaxHeight = 100
+	f.SetAspectRatio(2, 1)
+
+	c.Assert(gforms.IsFieldValid(f, newFileHeader(c, "a.png", encodePNG(c, 40, 20))), Equals, true)
+	c.Assert(f.Format(), Equals, "png")
+	c.Assert(f.Config().Width, Equals, 40)
+	c.Assert(f.Config().Height, Equals, 20)
+	c.Assert(f.Value().Filename, Equals, "a.png")
+
+	c.Assert(gforms.IsFieldValid(f, newFileHeader(c, "a.png", encodePNG(c, 5, 200))), Equals, false)
+	c.Assert(imageErrorCodes(f), DeepEquals, []string{
+		gforms.CodeMinImageWidth, gforms.CodeMaxImageHeight, gforms.CodeAspectRatio,
+	})
+	c.Assert(f.ValidationErrors()[0].Error(), Equals, "Image width should be at least 10px")
+	c.Assert(f.ValidationErrors()[2].Error(), Equals, "Image aspect ratio should be 2:1")
+	c.Assert(f.Config(), Equals, image.Config{})
+	c.Assert(f.Value(), IsNil)
+}
+
+func (t *FieldsTest) TestImageFieldFormats(c *C) {
+	f := gforms.NewImageField()
+	f.Formats = []string{"png", "jpeg"}
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="file" accept="image/png,image/jpeg" />`))
+
+	buf := &bytes.Buffer{}
+	c.Assert(gif.Encode(buf, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black}), nil), IsNil)
+	c.Assert(gforms.IsFieldValid(f, newFileHeader(c, "a.gif", buf.Bytes())), Equals, false)
+	c.Assert(f.ValidationError(), ErrorMatches, `Image format gif is not allowed \(allowed: png, jpeg\)`)
+
+	c.Assert(gforms.IsFieldValid(f, newFileHeader(c, "a.png", []byte("not an image"))), Equals, false)
+	c.Assert(imageErrorCodes(f), DeepEquals, []string{gforms.CodeInvalidImage})
+
+	c.Assert(gforms.IsFieldValid(f, &multipart.FileHeader{Filename: "a.png"}), Equals, false)
+	c.Assert(imageErrorCodes(f), DeepEquals, []string{gforms.CodeReadFile})
+	c.Assert(f.ValidationError(), ErrorMatches, "Could not read uploaded file")
+}
+
+type AvatarForm struct {
+	*gforms.BaseForm
+	Avatar *gforms.ImageField `gforms:",maxwidth=64,ratio=1:1,formats=png,maxsize=1MB"`
+}
+
+func (t *FieldsTest) TestImageFieldTagOptions(c *C) {
+	f := &AvatarForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+	c.Assert(f.Avatar.MaxWidth, Equals, 64)
+	c.Assert(f.Avatar.Formats, DeepEquals, []string{"png"})
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.png": encodePNG(c, 64, 64)}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, true)
+
+	form = newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.png": encodePNG(c, 128, 64)}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(imageErrorCodes(f.Avatar), DeepEquals, []string{gforms.CodeMaxImageWidth, gforms.CodeAspectRatio})
+}
+
+//------------------------------------------------------------------------------
+
+func (t *FieldsTest) TestMultiFileField(c *C) {
+	f := gforms.NewMultiFileField()
+	f.SetName("docs")
+	f.MaxFiles = 2
+	f.AddValidator(gforms.NewFileExtensionValidator("pdf"))
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="file" multiple="multiple" id="docs" name="docs" accept=".pdf" />`))
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"docs": {"a.pdf": nil, "b.pdf": nil}})
+	c.Assert(gforms.IsFieldValid(f, form.File["docs"]), Equals, true)
+	c.Assert(f.Value(), HasLen, 2)
+
+	form = newMultipartForm(c, map[string]map[string][]byte{"docs": {"a.pdf": nil, "b.pdf": nil, "c.pdf": nil}})
+	c.Assert(gforms.IsFieldValid(f, form.File["docs"]), Equals, false)
+	c.Assert(f.ValidationError(), ErrorMatches, "Upload at most 2 files")
+	c.Assert(f.Value(), IsNil)
+}
+
+func (t *FieldsTest) TestMultiFileFieldReportsFailedFile(c *C) {
+	f := gforms.NewMultiFileField()
+	f.AddValidator(gforms.NewFileExtensionValidator("pdf"))
+	f.AddValidator(gforms.NewMaxFileSizeValidator(4))
+
+	files := newMultipartForm(c, map[string]map[string][]byte{"docs": {"a.pdf": nil}}).File["docs"]
+	files = append(files, newFileHeader(c, "b.exe", []byte("hello")))
+	c.Assert(gforms.IsFieldValid(f, files), Equals, false)
+
+	errs := f.ValidationErrors()
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `b.exe: File extension exe is not allowed \(allowed: pdf\)`)
+	c.Assert(errs[1], ErrorMatches, "b.exe: File size should be at most 4 bytes")
+	ferr := errs[1].(*gforms.FileError)
+	c.Assert(ferr.Filename, Equals, "b.exe")
+	c.Assert(ferr.Index, Equals, 1)
+	verr := ferr.Err.(*gforms.ValidationError)
+	c.Assert(verr.Code, Equals, gforms.CodeMaxFileSize)
+	c.Assert(verr.Params["max"], Equals, int64(4))
+
+	catalog := gforms.NewCatalog()
+	catalog.SetMessages("de", map[string]string{
+		gforms.CodeMaxFileSize: "Die Datei darf höchstens {max} Bytes groß sein",
+	})
+	c.Assert(
+		gforms.TranslateError(catalog.Translator("de"), errs[1]),
+		Equals,
+		"b.exe: Die Datei darf höchstens 4 Bytes groß sein",
+	)
+}
+
+type AttachmentsForm struct {
+	*gforms.BaseForm
+	Docs *gforms.MultiFileField `gforms:",required,minfiles=2,maxsize=1KB"`
+}
+
+func (t *FieldsTest) TestMultiFileFieldMultipartForm(c *C) {
+	f := &AttachmentsForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+
+	c.Assert(gforms.IsMultipartFormValid(f, newMultipartForm(c, nil)), Equals, false)
+	c.Assert(f.Errors()["Docs"][0], Equals, gforms.ErrRequired)
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Docs": {"a.txt": nil}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(f.Errors()["Docs"][0].(*gforms.ValidationError).Code, Equals, gforms.CodeMinFiles)
+
+	form = newMultipartForm(c, map[string]map[string][]byte{"Docs": {"a.txt": nil, "b.txt": []byte("b")}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, true)
+	c.Assert(f.Docs.Value(), HasLen, 2)
+}
+
+//------------------------------------------------------------------------------
+
+func (t *FieldsTest) TestEmailField(c *C) {
+	f := gforms.NewEmailField()
+	f.SetName("email")
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="email" id="email" name="email" value="" />`))
+
+	c.Assert(gforms.IsFieldValid(f, " John.Doe@Example.COM "), Equals, true)
+	c.Assert(f.Value(), Equals, "John.Doe@example.com")
+
+	for _, value := range []string{"foo", "foo@", "@example.com", "John <john@example.com>", "a@b@c"} {
+		c.Assert(gforms.IsFieldValid(f, value), Equals, false, Commentf("%s", value))
+		c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeInvalidEmail)
+	}
+
+	c.Assert(gforms.IsFieldValid(f, `"a b"@Example.COM`), Equals, true)
+	c.Assert(f.Value(), Equals, `"a b"@example.com`)
+	c.Assert(gforms.IsFieldValid(f, `"john..doe"@example.com`), Equals, true)
+	c.Assert(f.Value(), Equals, `"john..doe"@example.com`)
+	c.Assert(gforms.IsFieldValid(f, `"a\"b"@example.com`), Equals, true)
+	c.Assert(f.Value(), Equals, `"a\"b"@example.com`)
+	c.Assert(gforms.IsFieldValid(f, `"john"@example.com`), Equals, true)
+	c.Assert(f.Value(), Equals, "john@example.com")
+
+	c.Assert(gforms.IsFieldValid(f, "user@Bücher.example"), Equals, true)
+	c.Assert(f.Value(), Equals, "user@bücher.example")
+
+	f.IDNA = true
+	c.Assert(gforms.IsFieldValid(f, "user@Bücher.example"), Equals, true)
+	c.Assert(f.Value(), Equals, "user@xn--bcher-kva.example")
+	c.Assert(gforms.IsFieldValid(f, "user@münchen.de"), Equals, true)
+	c.Assert(f.Value(), Equals, "user@xn--mnchen-3ya.de")
+}
+
+func (t *FieldsTest) TestURLField(c *C) {
+	f := gforms.NewURLField()
+	f.SetName("url")
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="url" id="url" name="url" value="" />`))
+
+	c.Assert(gforms.IsFieldValid(f, "HTTP://Example.COM:8080/Path?q=1"), Equals, true)
+	c.Assert(f.Value(), Equals, "http://example.com:8080/Path?q=1")
+	c.Assert(f.URL().Port(), Equals, "8080")
+
+	c.Assert(gforms.IsFieldValid(f, "/path"), Equals, false)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeInvalidURL)
+	c.Assert(gforms.IsFieldValid(f, "http://"), Equals, false)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeInvalidURL)
+
+	c.Assert(gforms.IsFieldValid(f, "javascript:alert(1)"), Equals, false)
+	c.Assert(f.ValidationError(), ErrorMatches, `URL scheme javascript is not allowed \(allowed: http, https\)`)
+
+	c.Assert(gforms.IsFieldValid(f, "http:evil.com"), Equals, false)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeInvalidURL)
+
+	f.Schemes = []string{"https", "mailto"}
+	c.Assert(gforms.IsFieldValid(f, "mailto:user@example.com"), Equals, true)
+	f.DeniedHosts = []string{"example.com"}
+	c.Assert(gforms.IsFieldValid(f, "mailto:user@example.com"), Equals, false)
+	f.Schemes, f.DeniedHosts = nil, nil
+
+	f.AllowRelative = true
+	c.Assert(gforms.IsFieldValid(f, "/path"), Equals, true)
+	c.Assert(f.Render(), Equals, template.HTML(`<input type="text" id="url" name="url" value="/path" />`))
+}
+
+func (t *FieldsTest) TestURLFieldHosts(c *C) {
+	f := gforms.NewURLField()
+	f.AllowedHosts = []string{".example.com"}
+	f.DeniedHosts = []string{"evil.example.com"}
+	f.IDNA = true
+
+	c.Assert(gforms.IsFieldValid(f, "https://example.com"), Equals, true)
+	c.Assert(gforms.IsFieldValid(f, "https://www.Example.com/"), Equals, true)
+	c.Assert(gforms.IsFieldValid(f, "https://evil.example.com/"), Equals, false)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeURLHost)
+	c.Assert(gforms.IsFieldValid(f, "https://notexample.com/"), Equals, false)
+	c.Assert(gforms.IsFieldValid(f, "https://www.example.com./"), Equals, true)
+	c.Assert(f.Value(), Equals, "https://www.example.com/")
+
+	f.AllowedHosts = nil
+	f.DeniedHosts = []string{"evil.com"}
+	c.Assert(gforms.IsFieldValid(f, "https://evil.com./"), Equals, false)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeURLHost)
+	c.Assert(gforms.IsFieldValid(f, "https://./"), Equals, false)
+	c.Assert(gforms.IsFieldValid(f, "https://Bücher.example/"), Equals, false)
+	c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeURLHost)
+	c.Assert(gforms.IsFieldValid(f, "https://xn--bcher-kva.example/"), Equals, true)
+
+	f.DeniedHosts = nil
+	c.Assert(gforms.IsFieldValid(f, "https://Bücher.example/"), Equals, true)
+	c.Assert(f.Value(), Equals, "https://xn--bcher-kva.example/")
+	c.Assert(gforms.IsFieldValid(f, "http://[::1]:80/"), Equals, true)
+	c.Assert(f.Value(), Equals, "http://[::1]:80/")
+}
+
+func (t *FieldsTest) TestURLFieldUnicodeHostBypass(c *C) {
+	for _, idna := range []bool{false, true} {
+		f := gforms.NewURLField()
+		f.DeniedHosts = []string{"evil.com"}
+		f.IDNA = idna
+
+		for _, s := range []string{"http://ｅｖｉｌ.com/", "http://evil。com/", "http://evil．ｃｏｍ/", "http://ℯvil.com/"} {
+			c.Assert(gforms.IsFieldValid(f, s), Equals, false, Commentf("%s idna=%v", s, idna))
+			c.Assert(f.ValidationError().(*gforms.ValidationError).Code, Equals, gforms.CodeURLHost)
+		}
+
+		f.DeniedHosts = nil
+		f.AllowedHosts = []string{"good.com"}
+		c.Assert(gforms.IsFieldValid(f, "http://ｇｏｏｄ。com/"), Equals, true)
+		c.Assert(f.Value(), Equals, "http://good.com/")
+		c.Assert(gforms.IsFieldValid(f, "http://gооd.com/"), Equals, false)
+	}
+}
+
+type ContactForm struct {
+	*gforms.BaseForm
+	Email   *gforms.EmailField `gforms:",required,maxlen=50"`
+	Website *gforms.URLField
+}
+
+func (t *FieldsTest) TestEmailURLFieldsInitForm(c *C) {
+	f := &ContactForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+	c.Assert(f.Email.Render(), Equals, template.HTML(
+		`<input type="email" id="Email" name="Email" required="required" maxlength="50" value="" />`,
+	))
+	c.Assert(f.Website.Render(), Equals, template.HTML(`<input type="url" id="Website" name="Website" value="" />`))
+}
@@ -1,9 +1,11 @@
 package gforms
 
 import (
+	"fmt"
 	"mime/multipart"
 	"net/url"
 	"reflect"
+	"strings"
 )
 
 //------------------------------------------------------------------------------
@@ -15,6 +17,12 @@ func init() {
 	Register((*TextareaStringField)(nil), func() interface{} {
 		return NewTextareaStringField()
 	})
+	Register((*EmailField)(nil), func() interface{} {
+		return NewEmailField()
+	})
+	Register((*URLField)(nil), func() interface{} {
+		return NewURLField()
+	})
 	Register((*StringChoiceField)(nil), func() interface{} {
 		return NewSelectStringField()
 	})
@@ -24,6 +32,21 @@ func init() {
 	Register((*Int64ChoiceField)(nil), func() interface{} {
 		return NewSelectInt64Field()
 	})
+	Register((*Float64Field)(nil), func() interface{} {
+		return NewFloat64Field()
+	})
+	Register((*DecimalField)(nil), func() interface{} {
+		return NewDecimalField()
+	})
+	Register((*DateTimeField)(nil), func() interface{} {
+		return NewDateTimeField()
+	})
+	Register((*DateField)(nil), func() interface{} {
+		return NewDateField()
+	})
+	Register((*TimeField)(nil), func() interface{} {
+		return NewTimeField()
+	})
 	Register((*BoolField)(nil), func() interface{} {
 		return NewBoolField()
 	})
@@ -33,33 +56,102 @@ func init() {
 	Register((*MultiInt64ChoiceField)(nil), func() interface{} {
 		return NewMultiSelectInt64Field()
 	})
+	Register((*FileField)(nil), func() interface{} {
+		return NewFileField()
+	})
+	Register((*MultiFileField)(nil), func() interface{} {
+		return NewMultiFileField()
+	})
+	Register((*ImageField)(nil), func() interface{} {
+		return NewImageField()
+	})
 }
 
 //------------------------------------------------------------------------------
 
 type Form interface {
-	SetFields(map[string]Field)
+	SetFields([]Field)
 	Fields() map[string]Field
+	FieldList() []Field
+
+	SetErrors(map[string][]error)
+	Errors() map[string][]error
+	AddError(string, error)
+
+	SetTranslator(Translator)
+	Translator() Translator
+
+	SetTemplates(*TemplateSet)
+	Templates() *TemplateSet
 
-	SetErrors(map[string]error)
-	Errors() map[string]error
+	SetTheme(Theme)
+	Theme() Theme
+
+	SetHTML5Attrs([]string)
+	HTML5Attrs() []string
+
+	SetPrefix(string)
+	Prefix() string
+}
+
+// Cleaner is implemented by forms that need to validate fields against
+// each other. Clean is called by IsValid after all fields are validated
+// (values of invalid fields are zero). Errors for specific fields are
+// added with Form.AddError; returned error is added as non-field error.
+type Cleaner interface {
+	Clean() error
 }
 
+// InitForm creates form fields and nested forms described by the form
+// struct. Names of fields are prefixed with form Prefix. Struct fields
+// that hold pointer to another form are initialized as nested forms:
+// their fields are added to the form using prefix from the tag
+// (`gforms:",prefix=billing-"`) or field name followed by dot.
 func InitForm(form Form) error {
+	if i, ok := form.(initer); ok {
+		return i.initForm()
+	}
+
 	formv := reflect.ValueOf(form).Elem()
 	formt := formv.Type()
 	tinfo := tinfoMap.TypeInfo(formt)
+	if tinfo.err != nil {
+		return tinfo.err
+	}
 
-	fields := make(map[string]Field, len(tinfo.fields))
+	fields := make([]Field, 0, len(tinfo.fields))
 	for _, finfo := range tinfo.fields {
 		fv := formv.FieldByIndex(finfo.idx)
 		isNil := fv.IsNil()
+
+		if finfo.flags&fForm != 0 {
+			if isNil {
+				fv.Set(newForm(fv.Type()))
+			}
+			nested := fv.Interface().(Form)
+			nested.SetPrefix(form.Prefix() + finfo.prefix)
+			if err := InitForm(nested); err != nil {
+				return err
+			}
+			fields = append(fields, nested.FieldList()...)
+			continue
+		}
+
 		if isNil {
 			fv.Set(reflect.ValueOf(finfo.constr()))
 		}
 		f := fv.Interface().(Field)
+		if finfo.widget != "" {
+			if err := checkWidget(f, finfo.widget); err != nil {
+				return fmt.Errorf("gforms: field %s: %v", form.Prefix()+finfo.name, err)
+			}
+			f.SetWidget(widgetConstrs[finfo.widget]())
+			if f.HasName() {
+				f.SetName(f.Name())
+			}
+		}
 		if !f.HasName() {
-			f.SetName(finfo.name)
+			f.SetName(form.Prefix() + finfo.name)
 		}
 		if !f.HasLabel() {
 			f.SetLabel(finfo.label)
@@ -67,37 +159,112 @@ func InitForm(form Form) error {
 		if isNil {
 			f.SetIsRequired(finfo.flags&fReq != 0)
 		}
-		fields[f.Name()] = f
+		if err := applyFieldOptions(f, finfo.opts); err != nil {
+			return err
+		}
+		fields = append(fields, f)
 	}
 	form.SetFields(fields)
 
 	return nil
 }
 
-type valueGetterFunc func(Field) interface{}
+// newForm allocates form of pointer type typ. Embedded pointers to forms
+// (e.g. *BaseForm) are allocated too.
+func newForm(typ reflect.Type) reflect.Value {
+	v := reflect.New(typ.Elem())
+	elem := v.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		f := typ.Elem().Field(i)
+		if f.Anonymous && f.PkgPath == "" && isNestedFormType(f.Type) {
+			elem.Field(i).Set(newForm(f.Type))
+		}
+	}
+	return v
+}
 
-func IsValid(f Form, getValue valueGetterFunc) bool {
-	formv := reflect.ValueOf(f).Elem()
-	formt := formv.Type()
-	tinfo := tinfoMap.TypeInfo(formt)
+type nestedForm struct {
+	// name is the struct field name (or name from the tag).
+	name string
+	// key is the name of nested form errors that don't belong to any
+	// of its fields.
+	key  string
+	form Form
+}
 
-	errs := make(map[string]error, 0)
+// nestedForms returns initialized nested forms of form.
+func nestedForms(form Form) []nestedForm {
+	formv := reflect.ValueOf(form).Elem()
+	tinfo := tinfoMap.TypeInfo(formv.Type())
+
+	var forms []nestedForm
 	for _, finfo := range tinfo.fields {
+		if finfo.flags&fForm == 0 {
+			continue
+		}
 		fv := formv.FieldByIndex(finfo.idx)
 		if fv.IsNil() {
 			continue
 		}
+		forms = append(forms, nestedForm{
+			name: finfo.name,
+			key:  form.Prefix() + finfo.name,
+			form: fv.Interface().(Form),
+		})
+	}
+	return forms
+}
 
-		f := fv.Interface().(Field)
-		if !IsFieldValid(f, getValue(f)) {
-			errs[f.Name()] = f.ValidationError()
+type valueGetterFunc func(Field) interface{}
+
+func IsValid(f Form, getValue valueGetterFunc) bool {
+	if b, ok := f.(binder); ok {
+		b.bind(getValue)
+	}
+
+	errs := make(map[string][]error, 0)
+	for _, field := range f.FieldList() {
+		if !IsFieldValid(field, getValue(field)) {
+			errs[field.Name()] = field.ValidationErrors()
 		}
 	}
-	f.SetErrors(errs)
+	cleanForm(f, errs)
 
 	return len(f.Errors()) == 0
 }
 
+// cleanForm sets errors of form from fieldErrs and calls Clean of nested
+// forms and then of the form itself. Errors of nested forms are added to
+// the form: field errors under prefixed field names, non-field errors
+// under prefixed name of the nested form and errors added for unknown
+// names under names with nested form prefix.
+func cleanForm(form Form, fieldErrs map[string][]error) {
+	errs := make(map[string][]error)
+	for _, f := range form.FieldList() {
+		if e, ok := fieldErrs[f.Name()]; ok {
+			errs[f.Name()] = e
+		}
+	}
+	for _, nested := range nestedForms(form) {
+		cleanForm(nested.form, fieldErrs)
+		for name, e := range nested.form.Errors() {
+			if name == "" {
+				name = nested.key
+			} else if !strings.HasPrefix(name, nested.form.Prefix()) {
+				name = nested.form.Prefix() + name
+			}
+			errs[name] = e
+		}
+	}
+	form.SetErrors(errs)
+
+	if cleaner, ok := form.(Cleaner); ok {
+		if err := cleaner.Clean(); err != nil {
+			form.AddError("", err)
+		}
+	}
+}
+
 func IsFormValid(form Form, formValues url.Values) bool {
 	getValue := func(f Field) interface{} {
 		if f.IsMultipart() {
@@ -116,8 +283,17 @@ func IsFormValid(form Form, formValues url.Values) bool {
 	return IsValid(form, getValue)
 }
 
+// multipartValuer is implemented by fields that read several parts of
+// multipart form, e.g. StoredFileField.
+type multipartValuer interface {
+	multipartValue(*multipart.Form) interface{}
+}
+
 func IsMultipartFormValid(form Form, multipartForm *multipart.Form) bool {
 	getValue := func(f Field) interface{} {
+		if mv, ok := f.(multipartValuer); ok {
+			return mv.multipartValue(multipartForm)
+		}
 		if f.IsMultipart() {
 			if f.IsMulti() {
 				return multipartForm.File[f.Name()]
@@ -143,22 +319,132 @@ func IsMultipartFormValid(form Form, multipartForm *multipart.Form) bool {
 //------------------------------------------------------------------------------
 
 type BaseForm struct {
-	fields map[string]Field
-	errors map[string]error
+	fieldList  []Field
+	fields     map[string]Field
+	errors     map[string][]error
+	translator Translator
+	templates  *TemplateSet
+	theme      Theme
+	html5Attrs []string
+	prefix     string
 }
 
-func (f *BaseForm) SetFields(fields map[string]Field) {
-	f.fields = fields
+// SetFields sets form fields. Order of fields is preserved and
+// returned by FieldList.
+func (f *BaseForm) SetFields(fields []Field) {
+	f.fieldList = fields
+	f.fields = make(map[string]Field, len(fields))
+	for _, field := range fields {
+		f.fields[field.Name()] = field
+		if f.translator != nil {
+			field.SetTranslator(f.translator)
+		}
+		if f.templates != nil {
+			field.SetTemplates(f.templates)
+		}
+		if f.theme != nil {
+			field.SetTheme(f.theme)
+		}
+		if f.html5Attrs != nil {
+			field.SetHTML5Attrs(f.html5Attrs)
+		}
+	}
 }
 
 func (f *BaseForm) Fields() map[string]Field {
 	return f.fields
 }
 
-func (f *BaseForm) SetErrors(errors map[string]error) {
+// FieldList returns form fields in declaration order.
+func (f *BaseForm) FieldList() []Field {
+	return f.fieldList
+}
+
+func (f *BaseForm) SetErrors(errors map[string][]error) {
 	f.errors = errors
 }
 
-func (f *BaseForm) Errors() map[string]error {
+// Errors returns all validation errors by field name. Errors that
+// belong to the whole form are stored under empty name.
+func (f *BaseForm) Errors() map[string][]error {
 	return f.errors
 }
+
+// AddError adds validation error to the field with given name. Empty name
+// is used for errors that belong to the whole form. Name may be given
+// without form prefix, e.g. "City" for field "Billing.City" of nested form.
+func (f *BaseForm) AddError(name string, err error) {
+	if _, ok := f.fields[name]; !ok && name != "" {
+		if _, ok := f.fields[f.prefix+name]; ok {
+			name = f.prefix + name
+		}
+	}
+	if f.errors == nil {
+		f.errors = make(map[string][]error)
+	}
+	f.errors[name] = append(f.errors[name], ErrorList(err)...)
+	if field, ok := f.fields[name]; ok {
+		field.AddValidationError(err)
+	}
+}
+
+// SetTranslator sets translator that is used to render messages and
+// labels of the form and all its fields, e.g. per request locale.
+func (f *BaseForm) SetTranslator(t Translator) {
+	f.translator = t
+	for _, field := range f.fieldList {
+		field.SetTranslator(t)
+	}
+}
+
+func (f *BaseForm) Translator() Translator {
+	return f.translator
+}
+
+// SetTemplates sets templates that are used to render all form fields.
+func (f *BaseForm) SetTemplates(set *TemplateSet) {
+	f.templates = set
+	for _, field := range f.fieldList {
+		field.SetTemplates(set)
+	}
+}
+
+func (f *BaseForm) Templates() *TemplateSet {
+	return f.templates
+}
+
+// SetTheme sets theme that is used to render the form and all its fields.
+func (f *BaseForm) SetTheme(theme Theme) {
+	f.theme = theme
+	for _, field := range f.fieldList {
+		field.SetTheme(theme)
+	}
+}
+
+func (f *BaseForm) Theme() Theme {
+	return f.theme
+}
+
+// SetHTML5Attrs limits HTML5 constraint attributes rendered by all form
+// fields to names. Pass NoHTML5Attrs to rely on server-side validation
+// only (the same effect as novalidate on the form element).
+func (f *BaseForm) SetHTML5Attrs(names []string) {
+	f.html5Attrs = names
+	for _, field := range f.fieldList {
+		field.SetHTML5Attrs(names)
+	}
+}
+
+func (f *BaseForm) HTML5Attrs() []string {
+	return f.html5Attrs
+}
+
+// SetPrefix sets prefix of field names. It should be called before
+// InitForm, e.g. to put several forms of the same type on one page.
+func (f *BaseForm) SetPrefix(prefix string) {
+	f.prefix = prefix
+}
+
+func (f *BaseForm) Prefix() string {
+	return f.prefix
+}
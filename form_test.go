@@ -1,7 +1,11 @@
 package gforms_test
 
 import (
+	"errors"
 	"html/template"
+	"net/url"
+	"sort"
+	"strings"
 
 	. "launchpad.net/gocheck"
 
@@ -58,6 +62,298 @@ func (t *TestForm) TestFormUsage(c *C) {
 	c.Assert(
 		f.Age.Render(),
 		Equals,
-		template.HTML(`<input type="text" id="Age" name="Age" value="23" />`),
+		template.HTML(`<input type="number" id="Age" name="Age" value="23" />`),
 	)
 }
+
+//------------------------------------------------------------------------------
+
+type OrderedForm struct {
+	*gforms.BaseForm
+	Zeta  *gforms.StringField
+	Alpha *gforms.StringField
+	Token *gforms.StringField
+	Mu    *gforms.Int64Field
+}
+
+func NewOrderedForm() *OrderedForm {
+	f := &OrderedForm{
+		BaseForm: &gforms.BaseForm{},
+		Token:    gforms.NewStringField(),
+	}
+	f.Token.SetWidget(gforms.NewHiddenWidget())
+	gforms.InitForm(f)
+	return f
+}
+
+func (t *FormTest) TestFieldListKeepsDeclarationOrder(c *C) {
+	f := NewOrderedForm()
+
+	names := make([]string, 0)
+	for _, field := range f.FieldList() {
+		names = append(names, field.Name())
+	}
+	c.Assert(names, DeepEquals, []string{"Zeta", "Alpha", "Token", "Mu"})
+	c.Assert(f.Fields()["Alpha"], Equals, gforms.Field(f.Alpha))
+}
+
+func (t *FormTest) TestRenderHiddenFields(c *C) {
+	f := NewOrderedForm()
+	f.Token.SetInitial("secret")
+
+	html, err := gforms.RenderHiddenFields(f)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<input type="hidden" id="Token" name="Token" value="secret" />`))
+}
+
+func (t *FormTest) TestRenderForm(c *C) {
+	f := NewOrderedForm()
+	f.Token.SetInitial("secret")
+	f.AddError("", errors.New("Form error"))
+
+	html, err := gforms.RenderForm(f)
+	c.Assert(err, IsNil)
+	s := string(html)
+
+	// Errors, hidden fields and then visible fields in declaration order.
+	indexes := make([]int, 0)
+	for _, part := range []string{
+		"Form error",
+		`<input type="hidden" id="Token" name="Token" value="secret" />`,
+		`id="Zeta"`,
+		`id="Alpha"`,
+		`id="Mu"`,
+	} {
+		i := strings.Index(s, part)
+		c.Assert(i >= 0, Equals, true, Commentf("%s not found in %s", part, s))
+		indexes = append(indexes, i)
+	}
+	c.Assert(sort.IntsAreSorted(indexes), Equals, true, Commentf("%s", s))
+	c.Assert(strings.Count(s, `id="Token"`), Equals, 1)
+}
+
+//------------------------------------------------------------------------------
+
+type PasswordForm struct {
+	*gforms.BaseForm
+	Password        *gforms.StringField
+	ConfirmPassword *gforms.StringField
+}
+
+func NewPasswordForm() *PasswordForm {
+	f := &PasswordForm{
+		BaseForm: &gforms.BaseForm{},
+	}
+	gforms.InitForm(f)
+	return f
+}
+
+func (f *PasswordForm) Clean() error {
+	if f.Password.Value() == "" {
+		return errors.New("Password is not set")
+	}
+	if f.Password.Value() != f.ConfirmPassword.Value() {
+		f.AddError("ConfirmPassword", errors.New("Passwords do not match"))
+	}
+	return nil
+}
+
+func (t *FormTest) TestCleanerAddsFieldError(c *C) {
+	f := NewPasswordForm()
+
+	ok := gforms.IsFormValid(f, url.Values{
+		"Password":        {"foo"},
+		"ConfirmPassword": {"bar"},
+	})
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Errors()["ConfirmPassword"], HasLen, 1)
+	c.Assert(f.Errors()["ConfirmPassword"][0].Error(), Equals, "Passwords do not match")
+	c.Assert(f.ConfirmPassword.ValidationError(), Equals, f.Errors()["ConfirmPassword"][0])
+}
+
+func (t *FormTest) TestCleanerAddsNonFieldError(c *C) {
+	f := NewPasswordForm()
+
+	c.Assert(gforms.IsFormValid(f, url.Values{}), Equals, false)
+	c.Assert(f.Errors()[""], HasLen, 1)
+	c.Assert(f.Errors()[""][0].Error(), Equals, "Password is not set")
+
+	html, err := gforms.RenderErrors(f)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<div class="alert alert-error">Password is not set</div>`+"\n"))
+}
+
+func (t *FormTest) TestCleanerPasses(c *C) {
+	f := NewPasswordForm()
+
+	ok := gforms.IsFormValid(f, url.Values{
+		"Password":        {"foo"},
+		"ConfirmPassword": {"foo"},
+	})
+	c.Assert(ok, Equals, true)
+	c.Assert(f.Errors(), HasLen, 0)
+}
+
+//------------------------------------------------------------------------------
+
+type AddressForm struct {
+	*gforms.BaseForm
+	Street *gforms.StringField `gforms:",required"`
+	City   *gforms.StringField `gforms:",required"`
+}
+
+func (f *AddressForm) Clean() error {
+	if f.Street.Value() == "Unknown st." {
+		f.AddError("Street", errors.New("Unknown street"))
+	}
+	if f.City.Value() == "Nowhere" {
+		return errors.New("We don't deliver to Nowhere")
+	}
+	return nil
+}
+
+type OrderForm struct {
+	*gforms.BaseForm
+	Name     *gforms.StringField
+	Billing  *AddressForm
+	Shipping *AddressForm `gforms:",prefix=shipping-"`
+}
+
+func NewOrderForm() *OrderForm {
+	f := &OrderForm{
+		BaseForm: &gforms.BaseForm{},
+	}
+	if err := gforms.InitForm(f); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (t *FormTest) TestNestedFormNames(c *C) {
+	f := NewOrderForm()
+
+	names := make([]string, 0)
+	for _, field := range f.FieldList() {
+		names = append(names, field.Name())
+	}
+	c.Assert(names, DeepEquals, []string{
+		"Name",
+		"Billing.Street", "Billing.City",
+		"shipping-Street", "shipping-City",
+	})
+	c.Assert(f.Billing.Prefix(), Equals, "Billing.")
+	c.Assert(f.Billing.Street.Name(), Equals, "Billing.Street")
+	c.Assert(f.Fields()["shipping-City"], Equals, gforms.Field(f.Shipping.City))
+}
+
+func (t *FormTest) TestNestedFormIsValid(c *C) {
+	f := NewOrderForm()
+
+	ok := gforms.IsFormValid(f, url.Values{
+		"Name":            {"foo"},
+		"Billing.Street":  {"Main st."},
+		"Billing.City":    {"Nowhere"},
+		"shipping-Street": {"Main st."},
+	})
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Billing.Street.Value(), Equals, "Main st.")
+	c.Assert(f.Errors(), HasLen, 2)
+	c.Assert(f.Errors()["Billing"][0].Error(), Equals, "We don't deliver to Nowhere")
+	c.Assert(f.Errors()["shipping-City"][0], Equals, gforms.ErrRequired)
+	c.Assert(f.Shipping.Errors()["shipping-City"][0], Equals, gforms.ErrRequired)
+	c.Assert(f.Billing.Errors()[""], HasLen, 1)
+
+	ok = gforms.IsFormValid(f, url.Values{
+		"Billing.Street":  {"Main st."},
+		"Billing.City":    {"Springfield"},
+		"shipping-Street": {"Main st."},
+		"shipping-City":   {"Springfield"},
+	})
+	c.Assert(ok, Equals, true)
+	c.Assert(f.Errors(), HasLen, 0)
+	c.Assert(f.Billing.Errors(), HasLen, 0)
+}
+
+func (t *FormTest) TestNestedFormAddError(c *C) {
+	f := NewOrderForm()
+	f.Name.SetIsRequired(true)
+
+	ok := gforms.IsFormValid(f, url.Values{
+		"Billing.Street":  {"Unknown st."},
+		"Billing.City":    {"Nowhere"},
+		"shipping-Street": {"Main st."},
+		"shipping-City":   {"Springfield"},
+	})
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Errors()["Street"], HasLen, 0)
+	c.Assert(f.Errors()["Billing.Street"][0].Error(), Equals, "Unknown street")
+	c.Assert(f.Billing.Street.ValidationError().Error(), Equals, "Unknown street")
+
+	html, err := gforms.RenderForm(f)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(html), "We don&#39;t deliver to Nowhere"), Equals, true)
+	c.Assert(strings.Contains(string(html), "Unknown street"), Equals, true)
+}
+
+func (t *FormTest) TestNestedFormRender(c *C) {
+	f := NewOrderForm()
+
+	html, err := gforms.RenderField(f.Billing.Street, nil)
+	c.Assert(err, IsNil)
+	c.Assert(string(html), Matches, `(?s).*<input type="text" id="Billing.Street" name="Billing.Street" required="required" value="" />.*`)
+}
+
+type BadNestedForm struct {
+	*gforms.BaseForm
+	Billing *AddressForm `gforms:",required"`
+}
+
+func (t *FormTest) TestNestedFormUnsupportedOption(c *C) {
+	err := gforms.InitForm(&BadNestedForm{BaseForm: &gforms.BaseForm{}})
+	c.Assert(err, ErrorMatches, `gforms: gforms_test.BadNestedForm.Billing: tag option "required" is not supported by nested form`)
+}
+
+//------------------------------------------------------------------------------
+
+type TagsForm struct {
+	*gforms.BaseForm
+	Tags *gforms.MultiStringChoiceField `gforms:",widget=checkboxgroup,choices=a|b|c"`
+	Ids  *gforms.MultiInt64ChoiceField  `gforms:",choices=1|2"`
+}
+
+func (t *FormTest) TestMultiChoiceFieldsFromURLValues(c *C) {
+	f := &TagsForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+
+	ok := gforms.IsFormValid(f, url.Values{"Tags": {"a", "b"}, "Ids": {"1", "2"}})
+	c.Assert(ok, Equals, true)
+	c.Assert(f.Tags.Value(), DeepEquals, []string{"a", "b"})
+	c.Assert(f.Ids.Value(), DeepEquals, []int64{1, 2})
+
+	ok = gforms.IsFormValid(f, url.Values{"Tags": {"a", "d"}, "Ids": {"x"}})
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Errors()["Tags"][0].Error(), Equals, "d is invalid choice")
+	c.Assert(f.Errors()["Ids"][0].Error(), Equals, "This field should be an integer")
+}
+
+type PatternForm struct {
+	*gforms.BaseForm
+	Code *gforms.StringField `gforms:",pattern=[a-z]+"`
+}
+
+func (t *FormTest) TestInitFormTwiceDoesNotDuplicateValidators(c *C) {
+	f := &PatternForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+	c.Assert(gforms.InitForm(f), IsNil)
+
+	c.Assert(gforms.IsFormValid(f, url.Values{"Code": {"123"}}), Equals, false)
+	c.Assert(f.Errors()["Code"], HasLen, 1)
+	c.Assert(f.Code.ValidationError(), FitsTypeOf, &gforms.ValidationError{})
+
+	tags := &TagsForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(tags), IsNil)
+	c.Assert(gforms.InitForm(tags), IsNil)
+	c.Assert(gforms.IsFormValid(tags, url.Values{"Tags": {"x"}, "Ids": {"3"}}), Equals, false)
+	c.Assert(tags.Errors()["Tags"], HasLen, 1)
+	c.Assert(tags.Errors()["Ids"], HasLen, 1)
+}
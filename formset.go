@@ -0,0 +1,307 @@
+package gforms
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Error codes of the formset validation errors.
+const (
+	CodeMinForms = "min_forms"
+	CodeMaxForms = "max_forms"
+)
+
+// MaxFormSetForms limits number of forms built from the submitted count
+// of formset that has no Max.
+var MaxFormSetForms = 1000
+
+// FormSetValidator checks forms of the formset against each other, e.g.
+// that there are no duplicates. Forms are passed in submitted order
+// without deleted ones. Errors for specific fields are added with
+// FormSet.AddError; returned error is added as non-field error.
+type FormSetValidator func(forms []Form) error
+
+// binder is implemented by forms that build their fields from submitted
+// values, e.g. FormSet reads number of forms.
+type binder interface {
+	bind(getValue valueGetterFunc)
+}
+
+// initer is implemented by forms that are initialized by InitForm
+// without typeinfo.
+type initer interface {
+	initForm() error
+}
+
+// FormSet is a list of forms of the same type, e.g. invoice lines. Form
+// i gets prefix "<prefix>-<i>-" and number of forms is submitted in
+// hidden "<prefix>-count" field. FormSet implements Form so it is
+// initialized with InitForm, validated with IsFormValid or
+// IsMultipartFormValid and rendered with RenderForm.
+type FormSet struct {
+	BaseForm
+
+	// Min and Max limit number of not deleted forms. Zero Max means
+	// no limit.
+	Min, Max int
+	// Extra is number of blank forms created by InitForm.
+	Extra int
+	// CanDelete adds "<prefix>-<i>-delete" checkbox to every form.
+	// Deleted forms are not validated.
+	CanDelete bool
+	// CanOrder adds "<prefix>-<i>-order" field to every form. Forms
+	// are returned by ValidForms sorted by it.
+	CanOrder bool
+
+	name       string
+	newForm    func() Form
+	validators []FormSetValidator
+
+	count   *Int64Field
+	forms   []Form
+	deletes []*BoolField
+	orders  []*Int64Field
+	// bindErr is returned by Clean when forms can't be built from
+	// submitted count.
+	bindErr error
+}
+
+// NewFormSet returns formset with given prefix. newForm should return
+// new form that is not initialized yet, e.g.
+//
+//	func() gforms.Form { return &LineForm{BaseForm: &gforms.BaseForm{}} }
+//
+// because formset sets prefix of the form before calling InitForm.
+func NewFormSet(prefix string, newForm func() Form) *FormSet {
+	count := NewInt64Field()
+	count.SetWidget(NewHiddenWidget())
+	count.SetIsRequired(true)
+	count.SetMin(0)
+	return &FormSet{
+		name:    prefix,
+		newForm: newForm,
+		count:   count,
+	}
+}
+
+// AddValidator adds validator that checks forms of the formset against
+// each other.
+func (fs *FormSet) AddValidator(v FormSetValidator) {
+	fs.validators = append(fs.validators, v)
+}
+
+// CountField returns hidden field with number of forms.
+func (fs *FormSet) CountField() *Int64Field {
+	return fs.count
+}
+
+// Forms returns all forms including deleted ones.
+func (fs *FormSet) Forms() []Form {
+	return fs.forms
+}
+
+// IsDeleted reports whether form i is marked for deletion.
+func (fs *FormSet) IsDeleted(i int) bool {
+	return fs.CanDelete && fs.deletes[i].Value()
+}
+
+// ValidForms returns forms that are not deleted in submitted order
+// (or sorted by order field when CanOrder is set). It should be called
+// after formset is validated.
+func (fs *FormSet) ValidForms() []Form {
+	idx := fs.activeForms()
+	if fs.CanOrder {
+		sort.SliceStable(idx, func(a, b int) bool {
+			return fs.orderKey(idx[a]) < fs.orderKey(idx[b])
+		})
+	}
+	forms := make([]Form, 0, len(idx))
+	for _, i := range idx {
+		forms = append(forms, fs.forms[i])
+	}
+	return forms
+}
+
+// EmptyForm returns form with "<prefix>-__prefix__-" prefix that can be
+// used as a template to add forms on the client side.
+func (fs *FormSet) EmptyForm() (Form, error) {
+	return fs.buildForm(fs.Prefix() + fs.name + "-__prefix__-")
+}
+
+// DeleteField returns deletion marker of form i or nil.
+func (fs *FormSet) DeleteField(i int) *BoolField {
+	if !fs.CanDelete {
+		return nil
+	}
+	return fs.deletes[i]
+}
+
+// OrderField returns order marker of form i or nil.
+func (fs *FormSet) OrderField(i int) *Int64Field {
+	if !fs.CanOrder {
+		return nil
+	}
+	return fs.orders[i]
+}
+
+func (fs *FormSet) initForm() error {
+	n := fs.Extra
+	if n < fs.Min {
+		n = fs.Min
+	}
+	if fs.Max > 0 && n > fs.Max {
+		n = fs.Max
+	}
+	// Build form even when n is zero, so that errors in the form
+	// definition (e.g. invalid tags) are reported by InitForm.
+	if _, err := fs.EmptyForm(); err != nil {
+		return err
+	}
+	return fs.build(n)
+}
+
+func (fs *FormSet) bind(getValue valueGetterFunc) {
+	n := 0
+	if IsFieldValid(fs.count, getValue(fs.count)) {
+		n = int(fs.count.Value())
+	}
+	limit := MaxFormSetForms
+	if fs.Max > 0 {
+		// Allow one more form so that Clean reports max_forms error.
+		limit = fs.Max + 1
+	}
+	if n > limit {
+		n = limit
+	}
+	fs.bindErr = fs.build(n)
+}
+
+// build creates n forms and sets formset fields.
+func (fs *FormSet) build(n int) error {
+	fs.count.SetName(fs.Prefix() + fs.name + "-count")
+	fs.count.SetInitial(int64(n))
+
+	fs.forms = make([]Form, 0, n)
+	fs.deletes = make([]*BoolField, 0, n)
+	fs.orders = make([]*Int64Field, 0, n)
+	fields := []Field{fs.count}
+	for i := 0; i < n; i++ {
+		prefix := fs.Prefix() + fs.name + "-" + strconv.Itoa(i) + "-"
+		form, err := fs.buildForm(prefix)
+		if err != nil {
+			return err
+		}
+		fs.forms = append(fs.forms, form)
+		fields = append(fields, form.FieldList()...)
+
+		if fs.CanOrder {
+			order := NewInt64Field()
+			order.SetName(prefix + "order")
+			order.SetLabel("Order")
+			order.SetInitial(int64(i + 1))
+			fs.orders = append(fs.orders, order)
+			fields = append(fields, order)
+		}
+		if fs.CanDelete {
+			del := NewBoolField()
+			del.SetName(prefix + "delete")
+			del.SetLabel("Delete")
+			fs.deletes = append(fs.deletes, del)
+			fields = append(fields, del)
+		}
+	}
+	fs.SetFields(fields)
+	return nil
+}
+
+func (fs *FormSet) buildForm(prefix string) (Form, error) {
+	form := fs.newForm()
+	form.SetPrefix(prefix)
+	if err := InitForm(form); err != nil {
+		return nil, fmt.Errorf("gforms: formset %s: %v", fs.name, err)
+	}
+	return form, nil
+}
+
+// Clean validates forms that are not deleted, number of forms and
+// applies formset validators. Validation errors of deleted forms are
+// discarded.
+func (fs *FormSet) Clean() error {
+	if fs.bindErr != nil {
+		return fs.bindErr
+	}
+
+	fieldErrs := fs.Errors()
+	errs := make(map[string][]error)
+	if e, ok := fieldErrs[fs.count.Name()]; ok {
+		errs[fs.count.Name()] = e
+	}
+	for _, i := range fs.activeForms() {
+		form := fs.forms[i]
+		cleanForm(form, fieldErrs)
+		for name, e := range form.Errors() {
+			if name == "" {
+				name = fs.Prefix() + fs.name + "-" + strconv.Itoa(i)
+			}
+			errs[name] = e
+		}
+		if fs.CanOrder {
+			if e, ok := fieldErrs[fs.orders[i].Name()]; ok {
+				errs[fs.orders[i].Name()] = e
+			}
+		}
+	}
+	fs.SetErrors(errs)
+	for i, form := range fs.forms {
+		if fs.IsDeleted(i) {
+			form.SetErrors(nil)
+			for _, f := range form.FieldList() {
+				f.SetValidationError(nil)
+			}
+		}
+	}
+
+	var formErrs ValidationErrors
+	n := len(fs.activeForms())
+	if n < fs.Min {
+		formErrs.Add(NewValidationError(
+			CodeMinForms,
+			"Submit at least {min} forms",
+			map[string]interface{}{"min": fs.Min},
+		))
+	}
+	if fs.Max > 0 && n > fs.Max {
+		formErrs.Add(NewValidationError(
+			CodeMaxForms,
+			"Submit at most {max} forms",
+			map[string]interface{}{"max": fs.Max},
+		))
+	}
+	if len(fs.Errors()) == 0 {
+		forms := fs.ValidForms()
+		for _, v := range fs.validators {
+			formErrs.Add(v(forms))
+		}
+	}
+	return formErrs.Err()
+}
+
+func (fs *FormSet) activeForms() []int {
+	idx := make([]int, 0, len(fs.forms))
+	for i := range fs.forms {
+		if !fs.IsDeleted(i) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// orderKey returns sort key of form i. Forms without order go last.
+func (fs *FormSet) orderKey(i int) int64 {
+	order := fs.orders[i]
+	if order.iValue == nil {
+		return 1<<63 - 1
+	}
+	return order.Value()
+}
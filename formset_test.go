@@ -0,0 +1,172 @@
+package gforms_test
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type FormSetTest struct{}
+
+var _ = Suite(&FormSetTest{})
+
+//------------------------------------------------------------------------------
+
+type LineForm struct {
+	*gforms.BaseForm
+	Sku *gforms.StringField `gforms:",required"`
+	Qty *gforms.Int64Field  `gforms:",required,min=1"`
+}
+
+func NewLinesFormSet() *gforms.FormSet {
+	fs := gforms.NewFormSet("lines", func() gforms.Form {
+		return &LineForm{BaseForm: &gforms.BaseForm{}}
+	})
+	fs.Min = 1
+	fs.Max = 3
+	fs.Extra = 2
+	fs.CanDelete = true
+	fs.CanOrder = true
+	fs.AddValidator(func(forms []gforms.Form) error {
+		seen := make(map[string]bool)
+		for _, form := range forms {
+			line := form.(*LineForm)
+			if seen[line.Sku.Value()] {
+				fs.AddError(line.Sku.Name(), errors.New("Duplicate SKU"))
+			}
+			seen[line.Sku.Value()] = true
+		}
+		return nil
+	})
+	if err := gforms.InitForm(fs); err != nil {
+		panic(err)
+	}
+	return fs
+}
+
+func skus(forms []gforms.Form) []string {
+	ss := make([]string, 0)
+	for _, form := range forms {
+		ss = append(ss, form.(*LineForm).Sku.Value())
+	}
+	return ss
+}
+
+func (t *FormSetTest) TestInit(c *C) {
+	fs := NewLinesFormSet()
+
+	c.Assert(fs.Forms(), HasLen, 2)
+	c.Assert(fs.CountField().Name(), Equals, "lines-count")
+	c.Assert(fs.Fields()["lines-1-Qty"], NotNil)
+	c.Assert(fs.Fields()["lines-1-delete"], NotNil)
+	c.Assert(fs.Fields()["lines-1-order"], NotNil)
+
+	html, err := gforms.RenderHiddenFields(fs)
+	c.Assert(err, IsNil)
+	c.Assert(string(html), Equals, `<input type="hidden" id="lines-count" name="lines-count" value="2" />`)
+
+	form, err := fs.EmptyForm()
+	c.Assert(err, IsNil)
+	c.Assert(form.FieldList()[0].Name(), Equals, "lines-__prefix__-Sku")
+}
+
+func (t *FormSetTest) TestValidFormsInSubmittedOrder(c *C) {
+	fs := NewLinesFormSet()
+
+	ok := gforms.IsFormValid(fs, url.Values{
+		"lines-count":    {"3"},
+		"lines-0-Sku":    {"a"},
+		"lines-0-Qty":    {"1"},
+		"lines-0-order":  {"2"},
+		"lines-1-delete": {"true"},
+		"lines-2-Sku":    {"c"},
+		"lines-2-Qty":    {"3"},
+		"lines-2-order":  {"1"},
+	})
+	c.Assert(ok, Equals, true)
+	c.Assert(fs.Errors(), HasLen, 0)
+	c.Assert(fs.Forms(), HasLen, 3)
+	c.Assert(fs.IsDeleted(1), Equals, true)
+	c.Assert(skus(fs.ValidForms()), DeepEquals, []string{"c", "a"})
+
+	deleted := fs.Forms()[1].(*LineForm)
+	c.Assert(deleted.Sku.ValidationError(), IsNil)
+	c.Assert(deleted.Qty.ValidationError(), IsNil)
+}
+
+func (t *FormSetTest) TestFieldErrors(c *C) {
+	fs := NewLinesFormSet()
+
+	ok := gforms.IsFormValid(fs, url.Values{
+		"lines-count": {"2"},
+		"lines-0-Sku": {"a"},
+		"lines-0-Qty": {"0"},
+		"lines-1-Sku": {"b"},
+	})
+	c.Assert(ok, Equals, false)
+	c.Assert(fs.Errors(), HasLen, 2)
+	c.Assert(fs.Errors()["lines-0-Qty"], HasLen, 1)
+	c.Assert(fs.Errors()["lines-1-Qty"][0], Equals, gforms.ErrRequired)
+}
+
+func (t *FormSetTest) TestSetValidator(c *C) {
+	fs := NewLinesFormSet()
+
+	ok := gforms.IsFormValid(fs, url.Values{
+		"lines-count": {"2"},
+		"lines-0-Sku": {"a"},
+		"lines-0-Qty": {"1"},
+		"lines-1-Sku": {"a"},
+		"lines-1-Qty": {"2"},
+	})
+	c.Assert(ok, Equals, false)
+	c.Assert(fs.Errors()["lines-1-Sku"][0].Error(), Equals, "Duplicate SKU")
+	c.Assert(fs.Forms()[1].(*LineForm).Sku.ValidationError().Error(), Equals, "Duplicate SKU")
+}
+
+func (t *FormSetTest) TestMinMax(c *C) {
+	fs := NewLinesFormSet()
+
+	ok := gforms.IsFormValid(fs, url.Values{
+		"lines-count":    {"1"},
+		"lines-0-delete": {"true"},
+	})
+	c.Assert(ok, Equals, false)
+	c.Assert(fs.Errors()[""][0].(*gforms.ValidationError).Code, Equals, gforms.CodeMinForms)
+
+	values := url.Values{"lines-count": {"100"}}
+	for i := 0; i < 5; i++ {
+		prefix := "lines-" + strconv.Itoa(i) + "-"
+		values.Set(prefix+"Sku", strconv.Itoa(i))
+		values.Set(prefix+"Qty", "1")
+	}
+	c.Assert(gforms.IsFormValid(fs, values), Equals, false)
+	c.Assert(fs.Forms(), HasLen, 4)
+	c.Assert(fs.Errors()[""][0].Error(), Equals, "Submit at most 3 forms")
+}
+
+func (t *FormSetTest) TestInvalidCount(c *C) {
+	fs := NewLinesFormSet()
+
+	c.Assert(gforms.IsFormValid(fs, url.Values{"lines-count": {"foo"}}), Equals, false)
+	c.Assert(fs.Forms(), HasLen, 0)
+	c.Assert(fs.Errors()["lines-count"], HasLen, 1)
+}
+
+type BadLineForm struct {
+	*gforms.BaseForm
+	Qty *gforms.Int64Field `gforms:",min=foo"`
+}
+
+func (t *FormSetTest) TestInitReportsFormErrorsWithoutForms(c *C) {
+	fs := gforms.NewFormSet("lines", func() gforms.Form {
+		return &BadLineForm{BaseForm: &gforms.BaseForm{}}
+	})
+	err := gforms.InitForm(fs)
+	c.Assert(err, NotNil)
+	c.Assert(err, ErrorMatches, "gforms: formset lines: .*")
+}
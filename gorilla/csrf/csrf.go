@@ -71,7 +71,7 @@ func (f *Field) Value() string {
 func (f *Field) Validate(rawValue interface{}) error {
 	value, ok := rawValue.(string)
 	if !ok {
-		return fmt.Errorf("type %T is not supported")
+		return fmt.Errorf("type %T is not supported", rawValue)
 	}
 
 	if f.currentToken == "" {
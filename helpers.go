@@ -1,134 +1,35 @@
 package gforms
 
 import (
-	"bytes"
 	"html/template"
-	"os"
-	"path"
 	"reflect"
-	"sync"
 )
 
-type templatesMap struct {
-	L sync.RWMutex
-	M map[string]*template.Template
-}
-
 var emptyHTML = template.HTML("")
 
-var templates = &templatesMap{
-	M: make(map[string]*template.Template),
-}
-
-var (
-	WidgetTemplatePath   = rootDir() + "templates/gforms/widget.html"
-	CheckboxTemplatePath = rootDir() + "templates/gforms/checkbox.html"
-	RadioTemplatePath    = rootDir() + "templates/gforms/radio.html"
-)
-
-func rootDir() string {
-	return os.Getenv("ROOT_DIR")
-}
-
-func getTemplate(filepath string) *template.Template {
-	templates.L.RLock()
-	t, ok := templates.M[filepath]
-	if ok {
-		templates.L.RUnlock()
-		return t
-	}
-	templates.L.RUnlock()
-
-	t = template.New(path.Base(filepath))
-	t = t.Funcs(template.FuncMap{
-		"field":       RenderField,
-		"label":       RenderLabel,
-		"field_error": RenderError,
-	})
-	var err error
-	t, err = t.ParseFiles(filepath)
-	if err != nil {
-		panic(err)
-	}
-
-	templates.L.Lock()
-	templates.M[filepath] = t
-	templates.L.Unlock()
-
-	return t
-}
-
 func RenderErrors(form Form) (template.HTML, error) {
-	errors := form.Errors()
-	if len(errors) == 0 {
-		return emptyHTML, nil
-	}
-
-	fields := form.Fields()
-	s := ""
-	for name, e := range errors {
-		field, ok := fields[name]
-		if name == "" || (ok && field.Widget().IsHidden()) {
-			s += `<div class="alert alert-error">` + e.Error() + `</div>` + "\n"
-		}
-	}
-	return template.HTML(s), nil
+	return formTheme(form).RenderErrors(form), nil
 }
 
+// Render renders field together with its label, errors and help text
+// using field theme.
 func Render(field Field, attrs ...string) (template.HTML, error) {
 	if reflect.ValueOf(field).IsNil() {
 		return emptyHTML, nil
 	}
-
-	data := struct {
-		Field  Field
-		Attrs  []string
-		Radios []template.HTML
-	}{
-		Field: field,
-		Attrs: attrs,
-	}
-
-	var t *template.Template
-	switch widget := field.Widget().(type) {
-	case *HiddenWidget:
-		return RenderField(field, attrs)
-	case *CheckboxWidget:
-		t = getTemplate(CheckboxTemplatePath)
-	case *RadioWidget:
-		data.Radios = widget.Radios(attrs, field.(SingleValueField).StringValue())
-		t = getTemplate(RadioTemplatePath)
-	default:
-		t = getTemplate(WidgetTemplatePath)
-	}
-
-	buf := &bytes.Buffer{}
-	if err := t.Execute(buf, data); err != nil {
-		return emptyHTML, err
-	}
-
-	return template.HTML(buf.String()), nil
+	return fieldTheme(field).Render(field, attrs)
 }
 
 func RenderError(f Field) (template.HTML, error) {
-	err := f.ValidationError()
-	if err == nil {
-		return emptyHTML, nil
-	}
-	s := `<span class="help-inline">` + err.Error() + `</span>`
-	return template.HTML(s), nil
+	return fieldTheme(f).RenderError(f), nil
 }
 
 func RenderLabel(f Field) (template.HTML, error) {
-	label := f.Label()
-	if label == "" {
-		return emptyHTML, nil
-	}
-	if f.IsRequired() {
-		label += "*"
-	}
-	s := `<label class="control-label" for="` + f.Name() + `">` + label + `</label>`
-	return template.HTML(s), nil
+	return fieldTheme(f).RenderLabel(f), nil
+}
+
+func RenderHelpText(f Field) (template.HTML, error) {
+	return fieldTheme(f).RenderHelpText(f), nil
 }
 
 func RenderField(f Field, attrs []string) (template.HTML, error) {
@@ -136,13 +37,38 @@ func RenderField(f Field, attrs []string) (template.HTML, error) {
 }
 
 func RenderHiddenFields(form Form) (template.HTML, error) {
-	fields := form.Fields()
-
 	var html template.HTML
-	for _, field := range fields {
+	for _, field := range form.FieldList() {
 		if field.Widget().IsHidden() {
 			html += field.Render()
 		}
 	}
 	return html, nil
 }
+
+// RenderForm renders form errors, hidden fields and then every visible
+// field in declaration order.
+func RenderForm(form Form) (template.HTML, error) {
+	html, err := RenderErrors(form)
+	if err != nil {
+		return emptyHTML, err
+	}
+
+	hidden, err := RenderHiddenFields(form)
+	if err != nil {
+		return emptyHTML, err
+	}
+	html += hidden
+
+	for _, field := range form.FieldList() {
+		if field.Widget().IsHidden() {
+			continue
+		}
+		fieldHTML, err := Render(field)
+		if err != nil {
+			return emptyHTML, err
+		}
+		html += fieldHTML
+	}
+	return html, nil
+}
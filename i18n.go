@@ -0,0 +1,137 @@
+package gforms
+
+import (
+	"errors"
+	"html/template"
+	"strings"
+	"sync"
+)
+
+// Key of the suffix that RenderLabel appends to labels of required fields.
+const LabelRequiredSuffixKey = "label_required_suffix"
+
+// Translator translates user-visible messages. Key is error code (e.g.
+// "required") or label (e.g. "First Name"). Params are substituted in the
+// translated message.
+type Translator interface {
+	Translate(key string, params map[string]interface{}) (string, bool)
+}
+
+type TranslatorFunc func(key string, params map[string]interface{}) (string, bool)
+
+func (f TranslatorFunc) Translate(key string, params map[string]interface{}) (string, bool) {
+	return f(key, params)
+}
+
+// Translate returns translated message for key or def when translator
+// is nil or does not know the key.
+func Translate(t Translator, key string, params map[string]interface{}, def string) string {
+	if t != nil {
+		if msg, ok := t.Translate(key, params); ok {
+			return msg
+		}
+	}
+	return formatMessage(def, params)
+}
+
+// TranslateError returns translated message of err. Only errors of
+// type *ValidationError are translated (by code).
+func TranslateError(t Translator, err error) string {
+	var ferr *FileError
+	if errors.As(err, &ferr) {
+		return ferr.Filename + ": " + TranslateError(t, ferr.Err)
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return Translate(t, verr.Code, verr.Params, verr.Message)
+	}
+	return err.Error()
+}
+
+// FieldLabel returns translated and escaped label of the field or
+// trusted label set with SetLabelHTML.
+func FieldLabel(f Field) template.HTML {
+	if html := f.LabelHTML(); html != "" {
+		return html
+	}
+	label := f.Label()
+	if label == "" {
+		return emptyHTML
+	}
+	return escapeHTML(Translate(f.Translator(), label, nil, label))
+}
+
+// FieldHelpText returns escaped help text of the field or trusted help
+// text set with SetHelpTextHTML.
+func FieldHelpText(f Field) template.HTML {
+	if html := f.HelpTextHTML(); html != "" {
+		return html
+	}
+	return escapeHTML(f.HelpText())
+}
+
+func escapeHTML(s string) template.HTML {
+	return template.HTML(template.HTMLEscapeString(s))
+}
+
+//------------------------------------------------------------------------------
+
+// Catalog holds messages for multiple locales. Message can refer to
+// params as {name}.
+type Catalog struct {
+	l        sync.RWMutex
+	messages map[string]map[string]string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		messages: make(map[string]map[string]string),
+	}
+}
+
+func (c *Catalog) Set(locale, key, message string) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	messages, ok := c.messages[locale]
+	if !ok {
+		messages = make(map[string]string)
+		c.messages[locale] = messages
+	}
+	messages[key] = message
+}
+
+func (c *Catalog) SetMessages(locale string, messages map[string]string) {
+	for key, message := range messages {
+		c.Set(locale, key, message)
+	}
+}
+
+func (c *Catalog) lookup(locale, key string) (string, bool) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+
+	for {
+		if msg, ok := c.messages[locale][key]; ok {
+			return msg, true
+		}
+		// Fall back from "de-AT" to "de".
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			return "", false
+		}
+		locale = locale[:i]
+	}
+}
+
+// Translator returns translator for the locale. Messages that are not
+// found for "de-AT" are looked up for "de".
+func (c *Catalog) Translator(locale string) Translator {
+	return TranslatorFunc(func(key string, params map[string]interface{}) (string, bool) {
+		msg, ok := c.lookup(locale, key)
+		if !ok {
+			return "", false
+		}
+		return formatMessage(msg, params), true
+	})
+}
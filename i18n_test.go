@@ -0,0 +1,98 @@
+package gforms_test
+
+import (
+	"html/template"
+	"net/url"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type I18nTest struct{}
+
+var _ = Suite(&I18nTest{})
+
+func newCatalog() *gforms.Catalog {
+	catalog := gforms.NewCatalog()
+	catalog.SetMessages("de", map[string]string{
+		gforms.CodeRequired:           "Dieses Feld ist erforderlich",
+		gforms.CodeMinLength:          "Mindestens {min} Zeichen",
+		gforms.LabelRequiredSuffixKey: " (Pflichtfeld)",
+		"Password":                    "Passwort",
+	})
+	return catalog
+}
+
+func (t *I18nTest) TestCatalogFallsBackToLanguage(c *C) {
+	tr := newCatalog().Translator("de-AT")
+
+	msg, ok := tr.Translate(gforms.CodeMinLength, map[string]interface{}{"min": 3})
+	c.Assert(ok, Equals, true)
+	c.Assert(msg, Equals, "Mindestens 3 Zeichen")
+
+	_, ok = tr.Translate("unknown", nil)
+	c.Assert(ok, Equals, false)
+}
+
+func (t *I18nTest) TestFormIsTranslated(c *C) {
+	catalog := newCatalog()
+
+	f := NewPasswordForm()
+	f.SetTranslator(catalog.Translator("de"))
+	f.Password.SetIsRequired(true)
+	f.ConfirmPassword.MinLen = 3
+
+	ok := gforms.IsFormValid(f, url.Values{"ConfirmPassword": {"x"}})
+	c.Assert(ok, Equals, false)
+
+	html, err := gforms.RenderError(f.Password)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<span class="help-inline">Dieses Feld ist erforderlich</span>`))
+
+	html, err = gforms.RenderError(f.ConfirmPassword)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<span class="help-inline">Mindestens 3 Zeichen</span>`))
+
+	html, err = gforms.RenderLabel(f.Password)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<label class="control-label" for="Password">Passwort (Pflichtfeld)</label>`))
+
+	html, err = gforms.RenderErrors(f)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<div class="alert alert-error">Password is not set</div>`+"\n"))
+
+	// Default messages are used for other forms.
+	f2 := NewPasswordForm()
+	f2.Password.SetIsRequired(true)
+	gforms.IsFormValid(f2, url.Values{})
+	html, err = gforms.RenderError(f2.Password)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<span class="help-inline">This field is required</span>`))
+}
+
+func (t *I18nTest) TestErrorCodesAreDistinct(c *C) {
+	catalog := gforms.NewCatalog()
+	catalog.SetMessages("de", map[string]string{
+		gforms.CodeInvalidInteger:  "Geben Sie eine ganze Zahl ein",
+		gforms.CodeInvalidDateTime: "Geben Sie ein gültiges Datum ein",
+		gforms.CodeMinValue:        "Mindestens {min}",
+		gforms.CodeMinDateTime:     "Nicht vor dem {min}",
+	})
+	tr := catalog.Translator("de")
+
+	n := gforms.NewInt64Field()
+	n.SetMin(1)
+	c.Assert(gforms.IsFieldValid(n, "x"), Equals, false)
+	c.Assert(gforms.TranslateError(tr, n.ValidationError()), Equals, "Geben Sie eine ganze Zahl ein")
+	c.Assert(gforms.IsFieldValid(n, "0"), Equals, false)
+	c.Assert(gforms.TranslateError(tr, n.ValidationError()), Equals, "Mindestens 1")
+
+	d := gforms.NewDateField()
+	d.SetMin(time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(gforms.IsFieldValid(d, "x"), Equals, false)
+	c.Assert(gforms.TranslateError(tr, d.ValidationError()), Equals, "Geben Sie ein gültiges Datum ein")
+	c.Assert(gforms.IsFieldValid(d, "2013-12-31"), Equals, false)
+	c.Assert(gforms.TranslateError(tr, d.ValidationError()), Equals, "Nicht vor dem 2014-01-01")
+}
@@ -0,0 +1,149 @@
+package gforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	CodeInvalidJSON  = "invalid_json"
+	CodeUnknownField = "unknown_field"
+)
+
+// IsJSONFormValid decodes JSON object from r and validates form with its
+// values. Arrays are used for multi fields, numbers and booleans are
+// passed to fields as strings and bools. Unknown keys are ignored.
+func IsJSONFormValid(form Form, r io.Reader) bool {
+	return isJSONFormValid(form, r, false)
+}
+
+// IsStrictJSONFormValid is like IsJSONFormValid, but keys that do not
+// match any form field are reported as non-field errors.
+func IsStrictJSONFormValid(form Form, r io.Reader) bool {
+	return isJSONFormValid(form, r, true)
+}
+
+func isJSONFormValid(form Form, r io.Reader, strict bool) bool {
+	values, err := decodeJSONObject(r)
+	if err != nil {
+		for _, f := range form.FieldList() {
+			f.Reset()
+		}
+		form.SetErrors(map[string][]error{
+			"": {NewValidationError(CodeInvalidJSON, "Request body is not valid JSON object", nil)},
+		})
+		return false
+	}
+
+	getValue := func(f Field) interface{} {
+		if f.IsMultipart() {
+			return nil
+		}
+		jsonValue, ok := values[f.Name()]
+		if !ok {
+			return nil
+		}
+		value, err := jsonToRawValue(jsonValue, f.IsMulti())
+		if err != nil {
+			return invalidRawValue{err}
+		}
+		return value
+	}
+	isValid := IsValid(form, getValue)
+
+	if strict {
+		names := make([]string, 0)
+		for name := range values {
+			if _, ok := form.Fields()[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			form.AddError("", NewValidationError(
+				CodeUnknownField,
+				"Unknown field {name}",
+				map[string]interface{}{"name": name},
+			))
+			isValid = false
+		}
+	}
+
+	return isValid
+}
+
+func decodeJSONObject(r io.Reader) (map[string]interface{}, error) {
+	var values map[string]interface{}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		if err == io.EOF {
+			return map[string]interface{}{}, nil
+		}
+		return nil, err
+	}
+	if values == nil {
+		return nil, fmt.Errorf("gforms: JSON object expected")
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("gforms: unexpected data after JSON object")
+	}
+	return values, nil
+}
+
+// jsonToRawValue converts decoded JSON value to the value expected by
+// IsValid: string, bool or []interface{} for multi fields.
+func jsonToRawValue(value interface{}, isMulti bool) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	if isMulti {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		rawValues := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			rawValue, err := jsonScalar(v)
+			if err != nil {
+				return nil, err
+			}
+			rawValues = append(rawValues, rawValue)
+		}
+		return rawValues, nil
+	}
+
+	return jsonScalar(value)
+}
+
+func jsonScalar(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return nil, NewValidationError(
+		CodeUnsupportedType,
+		"Type {type} is not supported",
+		map[string]interface{}{"type": jsonTypeName(value)},
+	)
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
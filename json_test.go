@@ -0,0 +1,89 @@
+package gforms_test
+
+import (
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type JSONTest struct{}
+
+var _ = Suite(&JSONTest{})
+
+func (t *JSONTest) TestIsJSONFormValid(c *C) {
+	f := NewArticleForm()
+
+	ok := gforms.IsJSONFormValid(f, strings.NewReader(`{
+		"Title": "Hello",
+		"Rating": 5,
+		"IsPublic": true,
+		"Tags": ["go", "web"],
+		"Unknown": 1
+	}`))
+	c.Assert(ok, Equals, true)
+	c.Assert(f.Title.Value(), Equals, "Hello")
+	c.Assert(f.Rating.Value(), Equals, int64(5))
+	c.Assert(f.IsPublic.Value(), Equals, true)
+	c.Assert(f.Tags.Value(), DeepEquals, []string{"go", "web"})
+}
+
+func (t *JSONTest) TestInvalidValues(c *C) {
+	f := NewArticleForm()
+
+	ok := gforms.IsJSONFormValid(f, strings.NewReader(`{
+		"Title": {"foo": "bar"},
+		"Rating": 1.5,
+		"Tags": "go"
+	}`))
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Errors()["Title"][0].Error(), Equals, "Type object is not supported")
+	c.Assert(f.Title.ValidationError(), Equals, f.Errors()["Title"][0])
+	c.Assert(f.Errors()["Rating"][0].Error(), Equals, "This field should be an integer")
+	c.Assert(f.Errors()["Tags"], HasLen, 0)
+	c.Assert(f.Tags.Value(), DeepEquals, []string{"go"})
+}
+
+type JSONCleanForm struct {
+	*gforms.BaseForm
+	Name *gforms.StringField
+
+	cleanErrs map[string][]error
+}
+
+func (f *JSONCleanForm) Clean() error {
+	f.cleanErrs = f.Errors()
+	return nil
+}
+
+func (t *JSONTest) TestInvalidValuesBeforeClean(c *C) {
+	f := &JSONCleanForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+
+	ok := gforms.IsJSONFormValid(f, strings.NewReader(`{"Name": ["foo"]}`))
+	c.Assert(ok, Equals, false)
+	c.Assert(f.cleanErrs["Name"], HasLen, 1)
+	c.Assert(f.cleanErrs["Name"][0], Equals, f.Name.ValidationError())
+}
+
+func (t *JSONTest) TestStrictMode(c *C) {
+	f := NewArticleForm()
+
+	ok := gforms.IsStrictJSONFormValid(f, strings.NewReader(`{"Title": "Hello", "b": 1, "a": 2}`))
+	c.Assert(ok, Equals, false)
+	c.Assert(f.Errors()[""], HasLen, 2)
+	c.Assert(f.Errors()[""][0].Error(), Equals, "Unknown field a")
+	c.Assert(f.Errors()[""][1].(*gforms.ValidationError).Code, Equals, gforms.CodeUnknownField)
+}
+
+func (t *JSONTest) TestMalformedJSON(c *C) {
+	for _, body := range []string{`{"Title": `, `[1, 2]`, `{} {}`} {
+		f := NewArticleForm()
+		c.Assert(gforms.IsJSONFormValid(f, strings.NewReader(body)), Equals, false)
+		c.Assert(f.Errors()[""][0].(*gforms.ValidationError).Code, Equals, gforms.CodeInvalidJSON)
+	}
+
+	f := NewArticleForm()
+	c.Assert(gforms.IsJSONFormValid(f, strings.NewReader("")), Equals, true)
+}
@@ -0,0 +1,306 @@
+package gforms
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagOptions lists key=value options supported by the gforms tag.
+var tagOptions = map[string]struct{}{
+	"name":        {},
+	"widget":      {},
+	"minlen":      {},
+	"maxlen":      {},
+	"min":         {},
+	"max":         {},
+	"step":        {},
+	"pattern":     {},
+	"help":        {},
+	"placeholder": {},
+	"choices":     {},
+	"prefix":      {},
+	"minsize":     {},
+	"maxsize":     {},
+	"extensions":  {},
+	"filetypes":   {},
+	"minwidth":    {},
+	"maxwidth":    {},
+	"minheight":   {},
+	"maxheight":   {},
+	"formats":     {},
+	"ratio":       {},
+	"minfiles":    {},
+	"maxfiles":    {},
+}
+
+// widgetConstrs maps widget names used in the gforms tag to widget
+// constructors.
+var widgetConstrs = map[string]func() Widget{
+	"text":          func() Widget { return NewTextWidget() },
+	"textarea":      func() Widget { return NewTextareaWidget() },
+	"hidden":        func() Widget { return NewHiddenWidget() },
+	"number":        func() Widget { return NewNumberWidget() },
+	"date":          func() Widget { return NewDateWidget() },
+	"time":          func() Widget { return NewTimeWidget() },
+	"datetime":      func() Widget { return NewDateTimeWidget() },
+	"checkbox":      func() Widget { return NewCheckboxWidget() },
+	"select":        func() Widget { return NewSelectWidget() },
+	"multiselect":   func() Widget { return NewMultiSelectWidget() },
+	"radio":         func() Widget { return NewRadioWidget() },
+	"checkboxgroup": func() Widget { return NewCheckboxGroupWidget() },
+	"file":          func() Widget { return NewFileWidget() },
+	"multifile":     func() Widget { return NewMultiFileWidget() },
+}
+
+// multiWidgets lists widgets that render multiple values.
+var multiWidgets = map[string]bool{
+	"multiselect":   true,
+	"checkboxgroup": true,
+	"multifile":     true,
+}
+
+// checkWidget reports error when widget set with widget tag option can't
+// render field: choice widgets require choice fields and vice versa, and
+// multi widgets require multi fields and vice versa.
+func checkWidget(f Field, name string) error {
+	_, isChoiceWidget := widgetConstrs[name]().(ChoiceWidget)
+	isChoiceField := reflect.ValueOf(f).MethodByName("SetChoices").IsValid()
+	if isChoiceWidget != isChoiceField || multiWidgets[name] != f.IsMulti() {
+		return fmt.Errorf("widget %s is not supported by %T", name, f)
+	}
+	return nil
+}
+
+var (
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	ratType     = reflect.TypeOf((*big.Rat)(nil))
+	timeType    = reflect.TypeOf(time.Time{})
+
+	stringChoicesType = reflect.TypeOf([]StringChoice(nil))
+	int64ChoicesType  = reflect.TypeOf([]Int64Choice(nil))
+)
+
+func applyFieldOptions(f Field, opts []fieldOption) error {
+	for _, opt := range opts {
+		if err := applyFieldOption(f, opt); err != nil {
+			return fmt.Errorf("gforms: field %s: %v", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+func applyFieldOption(f Field, opt fieldOption) error {
+	switch opt.key {
+	case "minlen":
+		return setIntStructField(f, "MinLen", opt)
+	case "maxlen":
+		return setIntStructField(f, "MaxLen", opt)
+	case "min":
+		return callSetter(f, "SetMin", opt)
+	case "max":
+		return callSetter(f, "SetMax", opt)
+	case "step":
+		return callSetter(f, "SetStep", opt)
+	case "pattern":
+		addOptionValidator(f, NewPatternValidator(opt.value))
+	case "help":
+		f.SetHelpText(opt.value)
+	case "placeholder":
+		f.Widget().Attrs().Set("placeholder", opt.value)
+	case "choices":
+		return setChoices(f, opt)
+	case "minsize", "maxsize", "extensions", "filetypes":
+		return addFileValidator(f, opt)
+	case "minfiles":
+		return setIntStructField(f, "MinFiles", opt)
+	case "maxfiles":
+		return setIntStructField(f, "MaxFiles", opt)
+	case "minwidth":
+		return setIntStructField(f, "MinWidth", opt)
+	case "maxwidth":
+		return setIntStructField(f, "MaxWidth", opt)
+	case "minheight":
+		return setIntStructField(f, "MinHeight", opt)
+	case "maxheight":
+		return setIntStructField(f, "MaxHeight", opt)
+	case "formats":
+		v := reflect.ValueOf(f).Elem().FieldByName("Formats")
+		if !v.IsValid() || v.Type() != reflect.TypeOf([]string(nil)) {
+			return errOptionNotSupported(f, opt)
+		}
+		v.Set(reflect.ValueOf(strings.Split(opt.value, "|")))
+	case "ratio":
+		img, ok := f.(*ImageField)
+		if !ok {
+			return errOptionNotSupported(f, opt)
+		}
+		w, h, _ := parseRatio(opt.value)
+		img.SetAspectRatio(w, h)
+	}
+	return nil
+}
+
+func errOptionNotSupported(f Field, opt fieldOption) error {
+	return fmt.Errorf("tag option %q is not supported by %T", opt.key, f)
+}
+
+func setIntStructField(f Field, name string, opt fieldOption) error {
+	v := reflect.ValueOf(f).Elem().FieldByName(name)
+	if !v.IsValid() || v.Kind() != reflect.Int {
+		return errOptionNotSupported(f, opt)
+	}
+	n, _ := strconv.Atoi(opt.value)
+	v.SetInt(int64(n))
+	return nil
+}
+
+func callSetter(f Field, name string, opt fieldOption) error {
+	setter := reflect.ValueOf(f).MethodByName(name)
+	if !setter.IsValid() || setter.Type().NumIn() != 1 {
+		return errOptionNotSupported(f, opt)
+	}
+
+	var arg interface{}
+	switch typ := setter.Type().In(0); typ {
+	case int64Type:
+		n, err := strconv.ParseInt(opt.value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("tag option %s=%s: value should be integer", opt.key, opt.value)
+		}
+		arg = n
+	case float64Type:
+		n, err := strconv.ParseFloat(opt.value, 64)
+		if err != nil {
+			return fmt.Errorf("tag option %s=%s: value should be number", opt.key, opt.value)
+		}
+		arg = n
+	case ratType:
+		r, ok := new(big.Rat).SetString(opt.value)
+		if !ok {
+			return fmt.Errorf("tag option %s=%s: value should be decimal number", opt.key, opt.value)
+		}
+		arg = r
+	case timeType:
+		tf, ok := f.(interface {
+			parse(string) (time.Time, bool)
+		})
+		if !ok {
+			return errOptionNotSupported(f, opt)
+		}
+		t, ok := tf.parse(opt.value)
+		if !ok {
+			return fmt.Errorf("tag option %s=%s: value should be valid date/time", opt.key, opt.value)
+		}
+		arg = t
+	default:
+		return errOptionNotSupported(f, opt)
+	}
+
+	setter.Call([]reflect.Value{reflect.ValueOf(arg)})
+	return nil
+}
+
+// addFileValidator adds file validator described by opt to file field.
+// Sizes are validated by parseOption.
+func addFileValidator(f Field, opt fieldOption) error {
+	if !f.IsMultipart() {
+		return errOptionNotSupported(f, opt)
+	}
+	switch opt.key {
+	case "minsize":
+		size, _ := parseFileSize(opt.value)
+		addOptionValidator(f, NewMinFileSizeValidator(size))
+	case "maxsize":
+		size, _ := parseFileSize(opt.value)
+		addOptionValidator(f, NewMaxFileSizeValidator(size))
+	case "extensions":
+		addOptionValidator(f, NewFileExtensionValidator(strings.Split(opt.value, "|")...))
+	case "filetypes":
+		addOptionValidator(f, NewFileTypeValidator(strings.Split(opt.value, "|")...))
+	}
+	return nil
+}
+
+// addOptionValidator adds validator created from tag option unless field
+// already has equal one, so options are not applied twice when InitForm
+// is called on initialized form.
+func addOptionValidator(f Field, v Validator) {
+	if bf, ok := f.(interface{ hasValidator(Validator) bool }); ok && bf.hasValidator(v) {
+		return
+	}
+	f.AddValidator(v)
+}
+
+func (f *BaseField) hasValidator(v Validator) bool {
+	for _, existing := range f.validators {
+		if pv, ok := v.(*PatternValidator); ok {
+			if epv, ok := existing.(*PatternValidator); ok && epv.Pattern == pv.Pattern {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(existing, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRatio parses aspect ratio in form "16:9".
+func parseRatio(s string) (w, h int, ok bool) {
+	ws, hs, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, false
+	}
+	w, errW := strconv.Atoi(ws)
+	h, errH := strconv.Atoi(hs)
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, false
+	}
+	return w, h, true
+}
+
+// setChoices parses choices in form "value1|value2:Label 2|...".
+// Label defaults to value.
+func setChoices(f Field, opt fieldOption) error {
+	setter := reflect.ValueOf(f).MethodByName("SetChoices")
+	if !setter.IsValid() || setter.Type().NumIn() != 1 {
+		return errOptionNotSupported(f, opt)
+	}
+
+	tokens := strings.Split(opt.value, "|")
+	switch setter.Type().In(0) {
+	case stringChoicesType:
+		choices := make([]StringChoice, 0, len(tokens))
+		for _, token := range tokens {
+			value, label, ok := strings.Cut(token, ":")
+			if !ok {
+				label = value
+			}
+			choices = append(choices, StringChoice{Value: value, Label: label})
+		}
+		setter.Call([]reflect.Value{reflect.ValueOf(choices)})
+	case int64ChoicesType:
+		choices := make([]Int64Choice, 0, len(tokens))
+		for _, token := range tokens {
+			value, label, ok := strings.Cut(token, ":")
+			if !ok {
+				label = value
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("tag option choices: %q is not integer", value)
+			}
+			choices = append(choices, Int64Choice{Value: n, Label: label})
+		}
+		setter.Call([]reflect.Value{reflect.ValueOf(choices)})
+	default:
+		return errOptionNotSupported(f, opt)
+	}
+	return nil
+}
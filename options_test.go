@@ -0,0 +1,136 @@
+package gforms_test
+
+import (
+	"html/template"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type OptionsTest struct{}
+
+var _ = Suite(&OptionsTest{})
+
+//------------------------------------------------------------------------------
+
+type TaggedForm struct {
+	*gforms.BaseForm
+	Title   *gforms.StringField       `gforms:"Title,required,minlen=3,maxlen=10,placeholder=Title\\, please"`
+	Body    *gforms.StringField       `gforms:",widget=textarea,help=Markdown is supported"`
+	Code    *gforms.StringField       `gforms:",pattern=[A-Z]{2\\,3},name=code"`
+	Rating  *gforms.Int64Field        `gforms:",min=1,max=5"`
+	Price   *gforms.Float64Field      `gforms:",min=0,step=0.01"`
+	Lang    *gforms.StringChoiceField `gforms:",choices=go:Golang|py"`
+	Level   *gforms.Int64ChoiceField  `gforms:",widget=radio,choices=1|2"`
+	Started *gforms.DateField         `gforms:",min=2014-01-01"`
+}
+
+func (t *OptionsTest) TestTagOptions(c *C) {
+	f := &TaggedForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+
+	c.Assert(f.Title.IsRequired(), Equals, true)
+	c.Assert(f.Title.MinLen, Equals, 3)
+	c.Assert(f.Title.MaxLen, Equals, 10)
+	c.Assert(f.Title.Render(), Equals, template.HTML(
+		`<input type="text" id="Title" name="Title" placeholder="Title, please" required="required" minlength="3" maxlength="10" value="" />`,
+	))
+
+	c.Assert(f.Body.HelpText(), Equals, "Markdown is supported")
+	c.Assert(f.Body.Render(), Equals, template.HTML(`<textarea id="Body" name="Body"></textarea>`))
+
+	c.Assert(f.Code.Name(), Equals, "code")
+	c.Assert(f.Fields()["code"], Equals, gforms.Field(f.Code))
+	c.Assert(gforms.IsFieldValid(f.Code, "ABCD"), Equals, false)
+	c.Assert(gforms.IsFieldValid(f.Code, "ABC"), Equals, true)
+
+	c.Assert(gforms.IsFieldValid(f.Rating, "6"), Equals, false)
+	c.Assert(f.Rating.ValidationError().Error(), Equals, "This field should be less than or equal to 5")
+
+	c.Assert(gforms.IsFieldValid(f.Price, "1.005"), Equals, false)
+	c.Assert(gforms.IsFieldValid(f.Price, "1.05"), Equals, true)
+
+	c.Assert(gforms.IsFieldValid(f.Lang, "py"), Equals, true)
+	c.Assert(gforms.IsFieldValid(f.Lang, "rb"), Equals, false)
+
+	c.Assert(f.Level.Widget(), FitsTypeOf, &gforms.RadioWidget{})
+	c.Assert(gforms.IsFieldValid(f.Level, "2"), Equals, true)
+
+	c.Assert(gforms.IsFieldValid(f.Started, "2013-12-31"), Equals, false)
+}
+
+type UnknownOptionForm struct {
+	*gforms.BaseForm
+	Title *gforms.StringField `gforms:",req"`
+}
+
+func (t *OptionsTest) TestUnknownOption(c *C) {
+	f := &UnknownOptionForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(
+		gforms.InitForm(f),
+		ErrorMatches,
+		`gforms: gforms_test.UnknownOptionForm.Title: unknown tag option "req"`,
+	)
+}
+
+type MalformedOptionForm struct {
+	*gforms.BaseForm
+	Title *gforms.StringField `gforms:",minlen=three"`
+}
+
+func (t *OptionsTest) TestMalformedOption(c *C) {
+	f := &MalformedOptionForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(
+		gforms.InitForm(f),
+		ErrorMatches,
+		`gforms: gforms_test.MalformedOptionForm.Title: tag option minlen=three: value should be non-negative integer`,
+	)
+}
+
+type UnsupportedOptionForm struct {
+	*gforms.BaseForm
+	IsPublic *gforms.BoolField `gforms:",maxlen=3"`
+}
+
+func (t *OptionsTest) TestUnsupportedOption(c *C) {
+	f := &UnsupportedOptionForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(
+		gforms.InitForm(f),
+		ErrorMatches,
+		`gforms: field IsPublic: tag option "maxlen" is not supported by \*gforms.BoolField`,
+	)
+}
+
+type TextareaChoiceForm struct {
+	*gforms.BaseForm
+	Lang *gforms.StringChoiceField `gforms:",widget=textarea,choices=a|b"`
+}
+
+type RadioMultiChoiceForm struct {
+	*gforms.BaseForm
+	Tags *gforms.MultiStringChoiceField `gforms:",widget=radio"`
+}
+
+type CheckboxGroupStringForm struct {
+	*gforms.BaseForm
+	Title *gforms.StringField `gforms:",widget=checkboxgroup"`
+}
+
+func (t *OptionsTest) TestUnsupportedWidget(c *C) {
+	c.Assert(
+		gforms.InitForm(&TextareaChoiceForm{BaseForm: &gforms.BaseForm{}}),
+		ErrorMatches,
+		`gforms: field Lang: widget textarea is not supported by \*gforms.StringChoiceField`,
+	)
+	c.Assert(
+		gforms.InitForm(&RadioMultiChoiceForm{BaseForm: &gforms.BaseForm{}}),
+		ErrorMatches,
+		`gforms: field Tags: widget radio is not supported by \*gforms.MultiStringChoiceField`,
+	)
+	c.Assert(
+		gforms.InitForm(&CheckboxGroupStringForm{BaseForm: &gforms.BaseForm{}}),
+		ErrorMatches,
+		`gforms: field Title: widget checkboxgroup is not supported by \*gforms.StringField`,
+	)
+}
@@ -0,0 +1,117 @@
+package gforms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidSignature = errors.New("gforms: invalid signature")
+
+// Signer signs values with HMAC-SHA256 so they can be passed through the
+// client (e.g. in hidden fields) and verified later. Values that should
+// not be readable by the client are encrypted with Encrypt instead. The
+// first key is used to sign and encrypt; all keys are accepted by Verify
+// and Decrypt, so keys are rotated by prepending new key and removing the
+// old one once signed values expire.
+type Signer struct {
+	Keys [][]byte
+}
+
+func NewSigner(keys ...[]byte) *Signer {
+	return &Signer{
+		Keys: keys,
+	}
+}
+
+// Sign returns data and its signature encoded as URL safe string.
+func (s *Signer) Sign(data []byte) string {
+	s.mustHaveKeys()
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(s.Keys[0], payload))
+}
+
+// Verify checks signature of value returned by Sign and returns signed
+// data.
+func (s *Signer) Verify(value string) ([]byte, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	for _, key := range s.Keys {
+		if hmac.Equal(mac, s.mac(key, payload)) {
+			data, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return nil, ErrInvalidSignature
+			}
+			return data, nil
+		}
+	}
+	return nil, ErrInvalidSignature
+}
+
+func (s *Signer) mac(key []byte, payload string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// Encrypt encrypts and authenticates data with AES-256-GCM and returns
+// result encoded as URL safe string.
+func (s *Signer) Encrypt(data []byte) string {
+	s.mustHaveKeys()
+	aead := s.aead(s.Keys[0])
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil))
+}
+
+// Decrypt decrypts value returned by Encrypt.
+func (s *Signer) Decrypt(value string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	for _, key := range s.Keys {
+		aead := s.aead(key)
+		if len(b) < aead.NonceSize() {
+			return nil, ErrInvalidSignature
+		}
+		nonce, ciphertext := b[:aead.NonceSize()], b[aead.NonceSize():]
+		if data, err := aead.Open(nil, nonce, ciphertext, nil); err == nil {
+			return data, nil
+		}
+	}
+	return nil, ErrInvalidSignature
+}
+
+// aead returns AES-256-GCM with key derived from the signing key, so the
+// same key is not used both for HMAC and encryption.
+func (s *Signer) aead(key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(s.mac(key, "gforms.Signer.Encrypt"))
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+func (s *Signer) mustHaveKeys() {
+	if len(s.Keys) == 0 {
+		panic("gforms: Signer has no keys")
+	}
+}
@@ -0,0 +1,61 @@
+package gforms_test
+
+import (
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type SignerTest struct{}
+
+var _ = Suite(&SignerTest{})
+
+func (t *SignerTest) TestSignVerify(c *C) {
+	s := gforms.NewSigner([]byte("key"))
+
+	value := s.Sign([]byte("hello"))
+	data, err := s.Verify(value)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "hello")
+
+	_, err = s.Verify(value + "x")
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+	_, err = s.Verify("aGVsbG8")
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+}
+
+func (t *SignerTest) TestKeyRotation(c *C) {
+	old := gforms.NewSigner([]byte("old"))
+	value := old.Sign([]byte("hello"))
+
+	s := gforms.NewSigner([]byte("new"), []byte("old"))
+	data, err := s.Verify(value)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "hello")
+
+	_, err = old.Verify(s.Sign([]byte("hello")))
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+
+	_, err = gforms.NewSigner([]byte("new")).Verify(value)
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+}
+
+func (t *SignerTest) TestEncryptDecrypt(c *C) {
+	old := gforms.NewSigner([]byte("old"))
+	value := old.Encrypt([]byte("secret password"))
+	c.Assert(strings.Contains(value, "secret"), Equals, false)
+
+	s := gforms.NewSigner([]byte("new"), []byte("old"))
+	data, err := s.Decrypt(value)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "secret password")
+
+	_, err = s.Decrypt(value[:len(value)-2])
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+	_, err = gforms.NewSigner([]byte("new")).Decrypt(value)
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+	_, err = s.Verify(value)
+	c.Assert(err, Equals, gforms.ErrInvalidSignature)
+}
@@ -0,0 +1,338 @@
+package gforms
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStoredFileMaxAge is default lifetime of stored file reference
+// rendered by StoredFileField.
+const DefaultStoredFileMaxAge = 24 * time.Hour
+
+var (
+	errInvalidStoredFile = NewValidationError(CodeInvalidStoredFile, "Stored file is invalid, upload it again", nil)
+	errStoredFileExpired = NewValidationError(CodeStoredFileExpired, "Stored file has expired, upload it again", nil)
+	// errSaveFile hides storage errors, which may contain file paths,
+	// from users.
+	errSaveFile = NewValidationError(CodeSaveFile, "Could not save file", nil)
+)
+
+// StoredFile describes uploaded file saved by Storage.
+type StoredFile struct {
+	// Key identifies file in the storage, e.g. path relative to the
+	// storage directory.
+	Key      string `json:"key"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	// ContentType is sniffed from the file content.
+	ContentType string `json:"content_type"`
+}
+
+// Storage saves uploaded files.
+type Storage interface {
+	Save(ctx context.Context, fh *multipart.FileHeader) (StoredFile, error)
+}
+
+// newStorageKey returns random key that keeps extension of filename.
+func newStorageKey(filename string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(b)
+	ext := strings.ToLower(path.Ext(filename))
+	if len(ext) > 1 && len(ext) <= 10 && strings.Trim(ext[1:], "abcdefghijklmnopqrstuvwxyz0123456789") == "" {
+		key += ext
+	}
+	return key, nil
+}
+
+func newStoredFile(fh *multipart.FileHeader) (StoredFile, error) {
+	key, err := newStorageKey(fh.Filename)
+	if err != nil {
+		return StoredFile{}, err
+	}
+	typ, err := DetectFileType(fh)
+	if err != nil {
+		return StoredFile{}, err
+	}
+	return StoredFile{
+		Key:         key,
+		Filename:    fh.Filename,
+		Size:        fh.Size,
+		ContentType: typ,
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+
+// FileSystemStorage saves files to Dir under random names.
+type FileSystemStorage struct {
+	Dir string
+}
+
+func NewFileSystemStorage(dir string) *FileSystemStorage {
+	return &FileSystemStorage{
+		Dir: dir,
+	}
+}
+
+func (s *FileSystemStorage) Save(ctx context.Context, fh *multipart.FileHeader) (StoredFile, error) {
+	if err := ctx.Err(); err != nil {
+		return StoredFile{}, err
+	}
+	stored, err := newStoredFile(fh)
+	if err != nil {
+		return StoredFile{}, err
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return StoredFile{}, err
+	}
+	defer src.Close()
+
+	name := s.Path(stored.Key)
+	dst, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return StoredFile{}, err
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		os.Remove(name)
+		return StoredFile{}, err
+	}
+	return stored, nil
+}
+
+// Path returns path of the stored file with given key.
+func (s *FileSystemStorage) Path(key string) string {
+	return filepath.Join(s.Dir, filepath.Base(key))
+}
+
+//------------------------------------------------------------------------------
+
+// MemoryStorage keeps files in memory. It is useful for tests.
+type MemoryStorage struct {
+	l     sync.RWMutex
+	files map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		files: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStorage) Save(ctx context.Context, fh *multipart.FileHeader) (StoredFile, error) {
+	if err := ctx.Err(); err != nil {
+		return StoredFile{}, err
+	}
+	stored, err := newStoredFile(fh)
+	if err != nil {
+		return StoredFile{}, err
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return StoredFile{}, err
+	}
+	defer src.Close()
+	b, err := io.ReadAll(src)
+	if err != nil {
+		return StoredFile{}, err
+	}
+
+	s.l.Lock()
+	s.files[stored.Key] = b
+	s.l.Unlock()
+	return stored, nil
+}
+
+// File returns content of the stored file.
+func (s *MemoryStorage) File(key string) ([]byte, bool) {
+	s.l.RLock()
+	b, ok := s.files[key]
+	s.l.RUnlock()
+	return b, ok
+}
+
+// Len returns number of stored files.
+func (s *MemoryStorage) Len() int {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	return len(s.files)
+}
+
+//------------------------------------------------------------------------------
+
+// storedFileToken is signed storedFileRef submitted in the hidden input
+// of StoredFileField.
+type storedFileToken string
+
+type storedFileRef struct {
+	File   StoredFile `json:"file"`
+	Issued time.Time  `json:"issued"`
+}
+
+// StoredFileField saves uploaded file to the storage once the file passes
+// validation. Reference to the stored file is rendered in signed hidden
+// input "<name>-stored", so user doesn't have to upload the file again
+// when other fields of the form are invalid. Use it with
+// IsMultipartFormValid.
+//
+// Files are saved before the whole form is valid, so some of them are
+// never used (e.g. user leaves the form). Storage doesn't remove them:
+// delete stored files that are not referenced by your data and are older
+// than MaxAge periodically.
+type StoredFileField struct {
+	*BaseField
+	// MaxAge limits lifetime of the reference to the stored file; expired
+	// reference is rejected. Default is DefaultStoredFileMaxAge.
+	MaxAge time.Duration
+
+	storage Storage
+	signer  *Signer
+	ctx     context.Context
+}
+
+func NewStoredFileField(storage Storage, signer *Signer) *StoredFileField {
+	return &StoredFileField{
+		BaseField: &BaseField{
+			widget:      NewFileWidget(),
+			isMultipart: true,
+		},
+		storage: storage,
+		signer:  signer,
+		ctx:     context.Background(),
+	}
+}
+
+// SetContext sets context passed to Storage.Save, e.g. request context.
+func (f *StoredFileField) SetContext(ctx context.Context) {
+	f.ctx = ctx
+}
+
+// StoredName returns name of the hidden input with stored file reference.
+func (f *StoredFileField) StoredName() string {
+	return f.Name() + "-stored"
+}
+
+func (f *StoredFileField) Value() *StoredFile {
+	if f.iValue == nil {
+		return nil
+	}
+	return f.iValue.(*StoredFile)
+}
+
+// multipartValue returns newly uploaded file or reference to the stored
+// one.
+func (f *StoredFileField) multipartValue(form *multipart.Form) interface{} {
+	if files := form.File[f.Name()]; len(files) > 0 {
+		return files[0]
+	}
+	if values := form.Value[f.StoredName()]; len(values) > 0 && values[0] != "" {
+		return storedFileToken(values[0])
+	}
+	return nil
+}
+
+func (f *StoredFileField) Validate(rawValue interface{}) error {
+	switch value := rawValue.(type) {
+	case *multipart.FileHeader:
+		if err := f.ApplyValidators(value); err != nil {
+			return err
+		}
+		stored, err := f.storage.Save(f.ctx, value)
+		if err != nil {
+			return errSaveFile
+		}
+		f.iValue = &stored
+		return nil
+	case storedFileToken:
+		b, err := f.signer.Verify(string(value))
+		if err != nil {
+			return errInvalidStoredFile
+		}
+		b, ok := bytes.CutPrefix(b, []byte(f.tokenPrefix()))
+		if !ok {
+			return errInvalidStoredFile
+		}
+		var ref storedFileRef
+		if err := json.Unmarshal(b, &ref); err != nil {
+			return errInvalidStoredFile
+		}
+		if time.Since(ref.Issued) > f.maxAge() {
+			return errStoredFileExpired
+		}
+		f.iValue = &ref.File
+		return nil
+	}
+	return errUnsupportedType(rawValue)
+}
+
+func (f *StoredFileField) SetInitial(initial *StoredFile) {
+	f.iValue = initial
+}
+
+func (f *StoredFileField) maxAge() time.Duration {
+	if f.MaxAge != 0 {
+		return f.MaxAge
+	}
+	return DefaultStoredFileMaxAge
+}
+
+// tokenPrefix binds signed reference to the field name.
+func (f *StoredFileField) tokenPrefix() string {
+	return "gforms.StoredFile:" + f.Name() + ":"
+}
+
+func (f *StoredFileField) token() (string, error) {
+	stored := f.Value()
+	if stored == nil {
+		return "", errors.New("gforms: no stored file")
+	}
+	b, err := json.Marshal(&storedFileRef{File: *stored, Issued: time.Now()})
+	if err != nil {
+		return "", err
+	}
+	return f.signer.Sign(append([]byte(f.tokenPrefix()), b...)), nil
+}
+
+// Render renders file input followed by hidden input with reference to
+// the stored file. File input is not required when file is stored.
+func (f *StoredFileField) Render(attrs ...string) template.HTML {
+	all := f.withHTML5Attrs(fileAccept(f.validators), attrs)
+	token, err := f.token()
+	if err != nil {
+		return f.widget.Render(all)
+	}
+
+	fileAttrs := make([]string, 0, len(all))
+	for i := 0; i+1 < len(all); i += 2 {
+		if all[i] != "required" {
+			fileAttrs = append(fileAttrs, all[i], all[i+1])
+		}
+	}
+	hidden := NewHiddenWidget().Render([]string{"name", f.StoredName()}, token)
+	return f.widget.Render(fileAttrs) + hidden
+}
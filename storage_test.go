@@ -0,0 +1,160 @@
+package gforms_test
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"os"
+	"strings"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type StorageTest struct{}
+
+var _ = Suite(&StorageTest{})
+
+//------------------------------------------------------------------------------
+
+func (t *StorageTest) TestFileSystemStorage(c *C) {
+	s := gforms.NewFileSystemStorage(c.MkDir())
+
+	stored, err := s.Save(context.Background(), newFileHeader(c, "A.PNG", pngHeader))
+	c.Assert(err, IsNil)
+	c.Assert(stored.Filename, Equals, "A.PNG")
+	c.Assert(stored.Size, Equals, int64(len(pngHeader)))
+	c.Assert(stored.ContentType, Equals, "image/png")
+	c.Assert(stored.Key, Matches, `[0-9a-f]{32}\.png`)
+
+	b, err := os.ReadFile(s.Path(stored.Key))
+	c.Assert(err, IsNil)
+	c.Assert(b, DeepEquals, pngHeader)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Save(ctx, newFileHeader(c, "a.txt", nil))
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (t *StorageTest) TestMemoryStorage(c *C) {
+	s := gforms.NewMemoryStorage()
+
+	stored, err := s.Save(context.Background(), newFileHeader(c, "a.txt", []byte("hello")))
+	c.Assert(err, IsNil)
+	c.Assert(stored.ContentType, Equals, "text/plain")
+	b, ok := s.File(stored.Key)
+	c.Assert(ok, Equals, true)
+	c.Assert(string(b), Equals, "hello")
+}
+
+type ProfileForm struct {
+	*gforms.BaseForm
+	Name   *gforms.StringField     `gforms:",required"`
+	Avatar *gforms.StoredFileField `gforms:",extensions=png"`
+}
+
+func NewProfileForm(storage gforms.Storage) *ProfileForm {
+	f := &ProfileForm{
+		BaseForm: &gforms.BaseForm{},
+		Avatar:   gforms.NewStoredFileField(storage, gforms.NewSigner([]byte("secret"))),
+	}
+	f.Avatar.SetIsRequired(true)
+	if err := gforms.InitForm(f); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func storedValue(c *C, html string) string {
+	const prefix = `<input type="hidden" name="Avatar-stored" value="`
+	i := strings.Index(html, prefix)
+	c.Assert(i >= 0, Equals, true)
+	value := html[i+len(prefix):]
+	return value[:strings.IndexByte(value, '"')]
+}
+
+func (t *StorageTest) TestStoredFileFieldKeepsUpload(c *C) {
+	storage := gforms.NewMemoryStorage()
+	f := NewProfileForm(storage)
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.png": pngHeader}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(f.Errors(), HasLen, 1)
+	c.Assert(f.Errors()["Name"], HasLen, 1)
+	c.Assert(storage.Len(), Equals, 1)
+	key := f.Avatar.Value().Key
+
+	html := string(f.Avatar.Render())
+	c.Assert(strings.HasPrefix(html, `<input type="file" id="Avatar" name="Avatar" accept=".png" />`), Equals, true)
+	token := storedValue(c, html)
+
+	// Re-submit without file.
+	f = NewProfileForm(storage)
+	form = newMultipartForm(c, nil)
+	form.Value = map[string][]string{"Name": {"Bob"}, "Avatar-stored": {token}}
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, true)
+	c.Assert(f.Avatar.Value().Key, Equals, key)
+	c.Assert(f.Avatar.Value().Filename, Equals, "a.png")
+	c.Assert(storage.Len(), Equals, 1)
+}
+
+func (t *StorageTest) TestStoredFileFieldValidation(c *C) {
+	storage := gforms.NewMemoryStorage()
+	f := NewProfileForm(storage)
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.exe": nil}})
+	form.Value = map[string][]string{"Name": {"Bob"}}
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(f.Errors()["Avatar"][0].(*gforms.ValidationError).Code, Equals, gforms.CodeFileExtension)
+	c.Assert(storage.Len(), Equals, 0)
+	c.Assert(string(f.Avatar.Render()), Equals,
+		`<input type="file" id="Avatar" name="Avatar" required="required" accept=".png" />`)
+
+	form = newMultipartForm(c, nil)
+	form.Value = map[string][]string{"Name": {"Bob"}, "Avatar-stored": {"forged"}}
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(f.Errors()["Avatar"][0], ErrorMatches, "Stored file is invalid, upload it again")
+
+	form.Value["Avatar-stored"] = nil
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(f.Errors()["Avatar"][0], Equals, gforms.ErrRequired)
+}
+
+func (t *StorageTest) TestStoredFileFieldExpiry(c *C) {
+	storage := gforms.NewMemoryStorage()
+	f := NewProfileForm(storage)
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.png": pngHeader}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	token := storedValue(c, string(f.Avatar.Render()))
+	time.Sleep(time.Millisecond)
+
+	f = NewProfileForm(storage)
+	f.Avatar.MaxAge = time.Millisecond
+	form = newMultipartForm(c, nil)
+	form.Value = map[string][]string{"Name": {"Bob"}, "Avatar-stored": {token}}
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	c.Assert(f.Errors()["Avatar"][0].(*gforms.ValidationError).Code, Equals, gforms.CodeStoredFileExpired)
+	c.Assert(f.Avatar.Value(), IsNil)
+}
+
+type failingStorage struct{}
+
+func (failingStorage) Save(ctx context.Context, fh *multipart.FileHeader) (gforms.StoredFile, error) {
+	return gforms.StoredFile{}, errors.New("open /var/uploads/x.png: permission denied")
+}
+
+func (t *StorageTest) TestStoredFileFieldSaveError(c *C) {
+	f := NewProfileForm(failingStorage{})
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.png": pngHeader}})
+	form.Value = map[string][]string{"Name": {"Bob"}}
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	verr := f.Errors()["Avatar"][0].(*gforms.ValidationError)
+	c.Assert(verr.Code, Equals, gforms.CodeSaveFile)
+	c.Assert(verr.Error(), Equals, "Could not save file")
+	c.Assert(f.Avatar.Value(), IsNil)
+}
@@ -0,0 +1,105 @@
+package gforms
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"sync"
+)
+
+// Names of the templates used by Render.
+const (
+	WidgetTemplate        = "widget.html"
+	CheckboxTemplate      = "checkbox.html"
+	RadioTemplate         = "radio.html"
+	CheckboxGroupTemplate = "checkbox_group.html"
+)
+
+//go:embed templates/*/*.html
+var embeddedTemplates embed.FS
+
+var defaultTemplates = struct {
+	l   sync.RWMutex
+	set *TemplateSet
+}{
+	set: NewTemplateSet(mustSub(embeddedTemplates, "templates/gforms")),
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// UseTemplates replaces default templates with templates from fsys.
+// Fsys should contain files named after WidgetTemplate, CheckboxTemplate,
+// RadioTemplate and CheckboxGroupTemplate in its root.
+func UseTemplates(fsys fs.FS) {
+	set := NewTemplateSet(fsys)
+	defaultTemplates.l.Lock()
+	defaultTemplates.set = set
+	defaultTemplates.l.Unlock()
+}
+
+// DefaultTemplates returns templates used by fields that have no own
+// template set.
+func DefaultTemplates() *TemplateSet {
+	defaultTemplates.l.RLock()
+	defer defaultTemplates.l.RUnlock()
+	return defaultTemplates.set
+}
+
+func fieldTemplates(f Field) *TemplateSet {
+	if set := f.Templates(); set != nil {
+		return set
+	}
+	return DefaultTemplates()
+}
+
+//------------------------------------------------------------------------------
+
+// TemplateSet loads templates from fs.FS and caches parsed templates.
+type TemplateSet struct {
+	fsys fs.FS
+
+	l sync.RWMutex
+	m map[string]*template.Template
+}
+
+func NewTemplateSet(fsys fs.FS) *TemplateSet {
+	return &TemplateSet{
+		fsys: fsys,
+		m:    make(map[string]*template.Template),
+	}
+}
+
+// Template returns parsed template with given name.
+func (s *TemplateSet) Template(name string) (*template.Template, error) {
+	s.l.RLock()
+	t, ok := s.m[name]
+	s.l.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	t = template.New(name)
+	t = t.Funcs(template.FuncMap{
+		"renderField":    RenderField,
+		"renderLabel":    RenderLabel,
+		"renderError":    RenderError,
+		"renderHelpText": RenderHelpText,
+		"fieldLabel":     FieldLabel,
+	})
+	t, err := t.ParseFS(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.l.Lock()
+	s.m[name] = t
+	s.l.Unlock()
+
+	return t, nil
+}
@@ -0,0 +1,56 @@
+package gforms_test
+
+import (
+	"html/template"
+	"strings"
+	"testing/fstest"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type TemplatesTest struct{}
+
+var _ = Suite(&TemplatesTest{})
+
+func (t *TemplatesTest) TestDefaultTemplatesAreEmbedded(c *C) {
+	f := gforms.NewStringField()
+	f.SetName("title")
+	f.SetLabel("Title")
+
+	html, err := gforms.Render(f)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(html), `<label class="control-label" for="title">Title</label>`), Equals, true)
+	c.Assert(strings.Contains(string(html), `<input type="text" id="title" name="title" value="" />`), Equals, true)
+}
+
+func (t *TemplatesTest) TestFormTemplates(c *C) {
+	set := gforms.NewTemplateSet(fstest.MapFS{
+		gforms.WidgetTemplate: &fstest.MapFile{
+			Data: []byte(`<p>{{renderLabel .Field}} {{renderField .Field .Attrs}}</p>`),
+		},
+	})
+
+	f := NewTestForm()
+	f.SetTemplates(set)
+
+	html, err := gforms.Render(f.Name)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(
+		`<p><label class="control-label" for="Name">Name</label> <input type="text" id="Name" name="Name" value="" /></p>`,
+	))
+
+	// Other forms still use default templates.
+	html, err = gforms.Render(NewTestForm().Name)
+	c.Assert(err, IsNil)
+	c.Assert(strings.HasPrefix(string(html), `<div class="control-group">`), Equals, true)
+}
+
+func (t *TemplatesTest) TestMissingTemplateIsReturnedAsError(c *C) {
+	f := gforms.NewBoolField()
+	f.SetTemplates(gforms.NewTemplateSet(fstest.MapFS{}))
+
+	_, err := gforms.Render(f)
+	c.Assert(err, NotNil)
+}
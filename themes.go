@@ -0,0 +1,352 @@
+package gforms
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Widget kinds used by themes.
+const (
+	KindInput         = "input"
+	KindTextarea      = "textarea"
+	KindSelect        = "select"
+	KindCheckbox      = "checkbox"
+	KindRadio         = "radio"
+	KindCheckboxGroup = "checkboxgroup"
+	KindFile          = "file"
+	KindHidden        = "hidden"
+)
+
+// WidgetKind returns kind of the widget that themes use to choose
+// template and classes.
+func WidgetKind(w Widget) string {
+	switch w.(type) {
+	case *HiddenWidget:
+		return KindHidden
+	case *TextareaWidget:
+		return KindTextarea
+	case *SelectWidget:
+		return KindSelect
+	case *CheckboxWidget:
+		return KindCheckbox
+	case *RadioWidget:
+		return KindRadio
+	case *CheckboxGroupWidget:
+		return KindCheckboxGroup
+	case *FileWidget:
+		return KindFile
+	}
+	if w.IsHidden() {
+		return KindHidden
+	}
+	return KindInput
+}
+
+// Theme controls markup produced by Render and other helpers.
+type Theme interface {
+	// Render renders field together with label, errors and help text.
+	Render(f Field, attrs []string) (template.HTML, error)
+	RenderLabel(f Field) template.HTML
+	RenderError(f Field) template.HTML
+	RenderHelpText(f Field) template.HTML
+	// RenderErrors renders non-field errors, errors of hidden fields and
+	// errors that don't belong to any field (e.g. of nested forms).
+	RenderErrors(form Form) template.HTML
+}
+
+var defaultTheme = struct {
+	l     sync.RWMutex
+	theme Theme
+}{
+	theme: LegacyTheme,
+}
+
+// UseTheme sets theme used by fields and forms that have no own theme.
+func UseTheme(theme Theme) {
+	defaultTheme.l.Lock()
+	defaultTheme.theme = theme
+	defaultTheme.l.Unlock()
+}
+
+func DefaultTheme() Theme {
+	defaultTheme.l.RLock()
+	defer defaultTheme.l.RUnlock()
+	return defaultTheme.theme
+}
+
+func fieldTheme(f Field) Theme {
+	if theme := f.Theme(); theme != nil {
+		return theme
+	}
+	return DefaultTheme()
+}
+
+func formTheme(form Form) Theme {
+	if theme := form.Theme(); theme != nil {
+		return theme
+	}
+	return DefaultTheme()
+}
+
+//------------------------------------------------------------------------------
+
+// TemplateTheme renders fields using templates from Templates and wraps
+// labels, errors and help text into configured tags.
+type TemplateTheme struct {
+	// Templates are used to render fields. When nil DefaultTemplates are
+	// used. Templates set on field take precedence.
+	Templates *TemplateSet
+
+	// InputClasses are added to the class attribute of widgets by
+	// widget kind.
+	InputClasses map[string]string
+	// InvalidInputClass is added to widgets of fields with errors.
+	InvalidInputClass string
+
+	LabelClass string
+
+	ErrorTag, ErrorClass     string
+	HelpTag, HelpClass       string
+	SummaryTag, SummaryClass string
+}
+
+func (t *TemplateTheme) templates(f Field) *TemplateSet {
+	if set := f.Templates(); set != nil {
+		return set
+	}
+	if t.Templates != nil {
+		return t.Templates
+	}
+	return DefaultTemplates()
+}
+
+// inputAttrs prepends theme classes to attrs. Class passed in attrs is
+// appended to theme classes.
+func (t *TemplateTheme) inputAttrs(f Field, kind string, attrs []string) []string {
+	classes := make([]string, 0, 3)
+	if class := t.InputClasses[kind]; class != "" {
+		classes = append(classes, class)
+	}
+	if f.HasValidationError() && t.InvalidInputClass != "" {
+		classes = append(classes, t.InvalidInputClass)
+	}
+	if len(classes) == 0 {
+		return attrs
+	}
+
+	newAttrs := make([]string, 0, len(attrs)+2)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i] == "class" {
+			classes = append(classes, attrs[i+1])
+			continue
+		}
+		newAttrs = append(newAttrs, attrs[i], attrs[i+1])
+	}
+	return append([]string{"class", strings.Join(classes, " ")}, newAttrs...)
+}
+
+func (t *TemplateTheme) Render(f Field, attrs []string) (template.HTML, error) {
+	kind := WidgetKind(f.Widget())
+	attrs = t.inputAttrs(f, kind, attrs)
+
+	data := struct {
+		Field      Field
+		Attrs      []string
+		Radios     []template.HTML
+		Checkboxes []template.HTML
+	}{
+		Field: f,
+		Attrs: attrs,
+	}
+
+	var name string
+	switch kind {
+	case KindHidden:
+		return f.Render(attrs...), nil
+	case KindCheckbox:
+		name = CheckboxTemplate
+	case KindRadio:
+		attrs = withFieldHTML5Attrs(f, attrs)
+		data.Radios = f.Widget().(*RadioWidget).Radios(attrs, f.(SingleValueField).StringValue())
+		name = RadioTemplate
+	case KindCheckboxGroup:
+		attrs = withFieldHTML5Attrs(f, attrs)
+		data.Checkboxes = f.Widget().(*CheckboxGroupWidget).Checkboxes(attrs, f.(MultiValueField).StringValue()...)
+		name = CheckboxGroupTemplate
+	default:
+		name = WidgetTemplate
+	}
+
+	tpl, err := t.templates(f).Template(name)
+	if err != nil {
+		return emptyHTML, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, data); err != nil {
+		return emptyHTML, err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// withFieldHTML5Attrs adds HTML5 constraint attributes of the field to
+// attrs for widgets rendered by the theme itself, e.g. radios.
+func withFieldHTML5Attrs(f Field, attrs []string) []string {
+	if bf, ok := f.(interface {
+		withHTML5Attrs(constraints, attrs []string) []string
+	}); ok {
+		return bf.withHTML5Attrs(nil, attrs)
+	}
+	return attrs
+}
+
+func (t *TemplateTheme) RenderLabel(f Field) template.HTML {
+	label := FieldLabel(f)
+	if label == "" {
+		return emptyHTML
+	}
+	if f.IsRequired() {
+		label += escapeHTML(Translate(f.Translator(), LabelRequiredSuffixKey, nil, "*"))
+	}
+	return template.HTML(
+		`<label` + classAttr(t.LabelClass) + ` for="` + template.HTMLEscapeString(f.Name()) + `">` +
+			string(label) + `</label>`)
+}
+
+func (t *TemplateTheme) RenderError(f Field) template.HTML {
+	s := ""
+	for _, err := range f.ValidationErrors() {
+		s += wrapTag(t.ErrorTag, t.ErrorClass, escapeHTML(TranslateError(f.Translator(), err)))
+	}
+	return template.HTML(s)
+}
+
+func (t *TemplateTheme) RenderHelpText(f Field) template.HTML {
+	text := FieldHelpText(f)
+	if text == "" {
+		return emptyHTML
+	}
+	return template.HTML(wrapTag(t.HelpTag, t.HelpClass, text))
+}
+
+func (t *TemplateTheme) RenderErrors(form Form) template.HTML {
+	errors := form.Errors()
+	if len(errors) == 0 {
+		return emptyHTML
+	}
+
+	s := ""
+	for _, e := range errors[""] {
+		s += wrapTag(t.SummaryTag, t.SummaryClass, escapeHTML(TranslateError(form.Translator(), e))) + "\n"
+	}
+	for _, field := range form.FieldList() {
+		if !field.Widget().IsHidden() {
+			continue
+		}
+		for _, e := range errors[field.Name()] {
+			s += wrapTag(t.SummaryTag, t.SummaryClass, escapeHTML(TranslateError(field.Translator(), e))) + "\n"
+		}
+	}
+	for _, name := range unknownErrorNames(form) {
+		for _, e := range errors[name] {
+			s += wrapTag(t.SummaryTag, t.SummaryClass, escapeHTML(TranslateError(form.Translator(), e))) + "\n"
+		}
+	}
+	return template.HTML(s)
+}
+
+// unknownErrorNames returns sorted names of form errors that don't belong
+// to any form field, e.g. non-field errors of nested forms.
+func unknownErrorNames(form Form) []string {
+	fields := form.Fields()
+	names := make([]string, 0)
+	for name := range form.Errors() {
+		if _, ok := fields[name]; !ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func classAttr(class string) string {
+	if class == "" {
+		return ""
+	}
+	return ` class="` + class + `"`
+}
+
+func wrapTag(tag, class string, html template.HTML) string {
+	if tag == "" {
+		return string(html)
+	}
+	return `<` + tag + classAttr(class) + `>` + string(html) + `</` + tag + `>`
+}
+
+//------------------------------------------------------------------------------
+
+var (
+	// LegacyTheme produces Bootstrap 2 markup using DefaultTemplates.
+	LegacyTheme = &TemplateTheme{
+		LabelClass:   "control-label",
+		ErrorTag:     "span",
+		ErrorClass:   "help-inline",
+		HelpTag:      "p",
+		HelpClass:    "help-block",
+		SummaryTag:   "div",
+		SummaryClass: "alert alert-error",
+	}
+
+	Bootstrap5Theme = &TemplateTheme{
+		Templates: NewTemplateSet(mustSub(embeddedTemplates, "templates/bootstrap5")),
+		InputClasses: map[string]string{
+			KindInput:         "form-control",
+			KindTextarea:      "form-control",
+			KindFile:          "form-control",
+			KindSelect:        "form-select",
+			KindCheckbox:      "form-check-input",
+			KindRadio:         "form-check-input",
+			KindCheckboxGroup: "form-check-input",
+		},
+		InvalidInputClass: "is-invalid",
+		LabelClass:        "form-label",
+		ErrorTag:          "div",
+		ErrorClass:        "invalid-feedback d-block",
+		HelpTag:           "div",
+		HelpClass:         "form-text",
+		SummaryTag:        "div",
+		SummaryClass:      "alert alert-danger",
+	}
+
+	TailwindTheme = &TemplateTheme{
+		Templates: NewTemplateSet(mustSub(embeddedTemplates, "templates/tailwind")),
+		InputClasses: map[string]string{
+			KindInput:         "block w-full rounded-md border-gray-300 shadow-sm",
+			KindTextarea:      "block w-full rounded-md border-gray-300 shadow-sm",
+			KindSelect:        "block w-full rounded-md border-gray-300 shadow-sm",
+			KindFile:          "block w-full text-sm",
+			KindCheckbox:      "h-4 w-4 rounded border-gray-300",
+			KindRadio:         "h-4 w-4 border-gray-300",
+			KindCheckboxGroup: "h-4 w-4 rounded border-gray-300",
+		},
+		InvalidInputClass: "border-red-500",
+		LabelClass:        "block text-sm font-medium text-gray-700",
+		ErrorTag:          "p",
+		ErrorClass:        "mt-1 text-sm text-red-600",
+		HelpTag:           "p",
+		HelpClass:         "mt-1 text-sm text-gray-500",
+		SummaryTag:        "div",
+		SummaryClass:      "mb-4 rounded-md bg-red-50 p-4 text-sm text-red-700",
+	}
+
+	// SemanticTheme produces plain HTML without classes.
+	SemanticTheme = &TemplateTheme{
+		Templates:  NewTemplateSet(mustSub(embeddedTemplates, "templates/semantic")),
+		ErrorTag:   "strong",
+		HelpTag:    "small",
+		SummaryTag: "p",
+	}
+)
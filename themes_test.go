@@ -0,0 +1,88 @@
+package gforms_test
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type ThemesTest struct{}
+
+var _ = Suite(&ThemesTest{})
+
+func (t *ThemesTest) TestLegacyThemeIsDefault(c *C) {
+	c.Assert(gforms.DefaultTheme(), Equals, gforms.Theme(gforms.LegacyTheme))
+}
+
+func (t *ThemesTest) TestBootstrap5Theme(c *C) {
+	f := NewPasswordForm()
+	f.SetTheme(gforms.Bootstrap5Theme)
+	f.Password.MinLen = 5
+	f.Password.SetHelpText("At least 5 symbols")
+
+	gforms.IsFormValid(f, url.Values{"Password": {"foo"}})
+
+	html, err := gforms.Render(f.Password, "class", "custom")
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<div class="mb-3">
+  <label class="form-label" for="Password">Password</label>
+  <input type="text" id="Password" name="Password" minlength="5" class="form-control is-invalid custom" value="" />
+  <div class="invalid-feedback d-block">This field should have at least 5 symbols</div>
+  <div class="form-text">At least 5 symbols</div>
+</div>
+`))
+
+	html, err = gforms.RenderErrors(f)
+	c.Assert(err, IsNil)
+	c.Assert(html, Equals, template.HTML(`<div class="alert alert-danger">Password is not set</div>`+"\n"))
+}
+
+func (t *ThemesTest) TestSemanticTheme(c *C) {
+	f := gforms.NewRadioStringField()
+	f.SetName("lang")
+	f.SetLabel("Language")
+	f.SetChoices([]gforms.StringChoice{{"go", "Golang"}})
+	f.SetTheme(gforms.SemanticTheme)
+
+	html, err := gforms.Render(f)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(html), "class="), Equals, false)
+	c.Assert(strings.Contains(string(html), `<legend>Language</legend>`), Equals, true)
+	c.Assert(strings.Contains(string(html), `<label><input type="radio" id="lang_0" name="lang" value="go" /> Golang</label>`), Equals, true)
+}
+
+func (t *ThemesTest) TestThemeRadiosRequired(c *C) {
+	f := gforms.NewRadioStringField()
+	f.SetName("lang")
+	f.SetChoices([]gforms.StringChoice{{"go", "Golang"}})
+	f.SetIsRequired(true)
+	f.SetTheme(gforms.SemanticTheme)
+
+	html, err := gforms.Render(f)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(html), `<input type="radio" id="lang_0" name="lang" required="required" value="go" />`), Equals, true)
+
+	f.SetHTML5Attrs(gforms.NoHTML5Attrs)
+	html, err = gforms.Render(f)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(html), "required"), Equals, false)
+}
+
+func (t *ThemesTest) TestTailwindThemeCheckboxGroup(c *C) {
+	f := gforms.NewCheckboxGroupStringField()
+	f.SetName("tags")
+	f.SetChoices([]gforms.StringChoice{{"go", "Go"}})
+	f.SetTheme(gforms.TailwindTheme)
+
+	html, err := gforms.Render(f)
+	c.Assert(err, IsNil)
+	c.Assert(
+		strings.Contains(string(html), `<input type="checkbox" id="tags_0" name="tags" class="h-4 w-4 rounded border-gray-300" value="go" /> Go`),
+		Equals,
+		true,
+	)
+}
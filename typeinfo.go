@@ -1,14 +1,19 @@
 package gforms
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 var (
-	fieldType = reflect.TypeOf((*Field)(nil)).Elem()
-	tinfoMap  = newTypeInfoMap()
+	fieldType  = reflect.TypeOf((*Field)(nil)).Elem()
+	formType   = reflect.TypeOf((*Form)(nil)).Elem()
+	binderType = reflect.TypeOf((*binder)(nil)).Elem()
+	tinfoMap   = newTypeInfoMap()
 )
 
 //------------------------------------------------------------------------------
@@ -17,28 +22,53 @@ type fieldFlags int
 
 const (
 	fReq fieldFlags = 1 << iota
+	// fForm marks struct field that holds nested form.
+	fForm
 )
 
+// fieldOption is key=value option from the gforms tag.
+type fieldOption struct {
+	key, value string
+}
+
 type fieldInfo struct {
 	idx    []int
 	name   string
 	label  string
 	constr constructor
 	flags  fieldFlags
+	widget string
+	opts   []fieldOption
+	// prefix is prepended to names of nested form fields.
+	prefix string
 }
 
 type typeInfo struct {
 	fields []*fieldInfo
+	// err is the first error found while parsing gforms tags.
+	err error
+}
+
+type modelFieldInfo struct {
+	idx  []int
+	name string
+}
+
+// modelInfo describes struct that is used by Decode and SetInitialFrom.
+type modelInfo struct {
+	fields map[string]*modelFieldInfo
 }
 
 type typeInfoMap struct {
-	l sync.RWMutex
-	m map[reflect.Type]*typeInfo
+	l  sync.RWMutex
+	m  map[reflect.Type]*typeInfo
+	mm map[reflect.Type]*modelInfo
 }
 
 func newTypeInfoMap() *typeInfoMap {
 	return &typeInfoMap{
-		m: make(map[reflect.Type]*typeInfo),
+		m:  make(map[reflect.Type]*typeInfo),
+		mm: make(map[reflect.Type]*modelInfo),
 	}
 }
 
@@ -53,10 +83,23 @@ func (m *typeInfoMap) TypeInfo(typ reflect.Type) *typeInfo {
 	tinfo = &typeInfo{}
 	for i := 0; i < typ.NumField(); i++ {
 		f := typ.Field(i)
-		if f.PkgPath != "" || !f.Type.Implements(fieldType) {
+		if f.PkgPath != "" {
+			continue
+		}
+		var finfo *fieldInfo
+		var err error
+		switch {
+		case f.Type.Implements(fieldType):
+			finfo, err = m.newStructFieldInfo(typ, &f)
+		case !f.Anonymous && isNestedFormType(f.Type):
+			finfo, err = m.newNestedFormInfo(typ, &f)
+		default:
 			continue
 		}
-		tinfo.fields = append(tinfo.fields, m.newStructFieldInfo(typ, &f))
+		if err != nil && tinfo.err == nil {
+			tinfo.err = err
+		}
+		tinfo.fields = append(tinfo.fields, finfo)
 	}
 
 	m.l.Lock()
@@ -66,29 +109,205 @@ func (m *typeInfoMap) TypeInfo(typ reflect.Type) *typeInfo {
 	return tinfo
 }
 
-func (m *typeInfoMap) newStructFieldInfo(typ reflect.Type, f *reflect.StructField) *fieldInfo {
+// newStructFieldInfo parses gforms tag, which has form
+// "Label,required,key=value,...". Commas in values are escaped with
+// backslash.
+func (m *typeInfoMap) newStructFieldInfo(typ reflect.Type, f *reflect.StructField) (*fieldInfo, error) {
 	finfo := &fieldInfo{
 		idx:    f.Index,
+		name:   f.Name,
 		constr: tconstrMap.Constructor(f.Type),
 	}
 
-	tokens := strings.Split(f.Tag.Get("gforms"), ",")
+	var err error
+	tokens := splitTag(f.Tag.Get("gforms"))
 	finfo.label = tokens[0]
-	if len(tokens) > 1 {
-		for _, flag := range tokens[1:] {
-			switch flag {
-			case "required":
-				finfo.flags |= fReq
-			}
+	for _, token := range tokens[1:] {
+		if e := finfo.parseOption(token); e != nil && err == nil {
+			err = fmt.Errorf("gforms: %s.%s: %v", typ, f.Name, e)
 		}
 	}
 
-	finfo.name = f.Name
 	if finfo.label == "" {
 		finfo.label = strings.Join(splitWords(f.Name), " ")
 	} else if finfo.label == "-" {
 		finfo.label = ""
 	}
 
-	return finfo
+	return finfo, err
+}
+
+// newNestedFormInfo parses gforms tag of struct field that holds nested
+// form. Only name and prefix options are supported. Prefix defaults to
+// name followed by dot, e.g. "Billing." for field Billing.
+func (m *typeInfoMap) newNestedFormInfo(typ reflect.Type, f *reflect.StructField) (*fieldInfo, error) {
+	finfo := &fieldInfo{
+		idx:   f.Index,
+		name:  f.Name,
+		flags: fForm,
+	}
+
+	var err error
+	tokens := splitTag(f.Tag.Get("gforms"))
+	for _, token := range tokens[1:] {
+		e := finfo.parseOption(token)
+		if e == nil && (finfo.flags&fReq != 0 || finfo.widget != "" || len(finfo.opts) > 0) {
+			e = fmt.Errorf("tag option %q is not supported by nested form", token)
+			finfo.flags &^= fReq
+			finfo.widget = ""
+			finfo.opts = nil
+		}
+		if e != nil && err == nil {
+			err = fmt.Errorf("gforms: %s.%s: %v", typ, f.Name, e)
+		}
+	}
+	if finfo.prefix == "" {
+		finfo.prefix = finfo.name + "."
+	}
+
+	return finfo, err
+}
+
+// isNestedFormType reports whether struct field of type typ holds nested
+// form, i.e. typ is pointer to struct that implements Form. Forms that
+// build fields from submitted values (FormSet) can't be nested.
+func isNestedFormType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Ptr &&
+		typ.Elem().Kind() == reflect.Struct &&
+		typ.Implements(formType) &&
+		!typ.Implements(binderType)
+}
+
+func (finfo *fieldInfo) parseOption(token string) error {
+	key, value, hasValue := strings.Cut(token, "=")
+	if !hasValue {
+		switch key {
+		case "required":
+			finfo.flags |= fReq
+			return nil
+		case "":
+			return fmt.Errorf("empty tag option")
+		}
+		if _, ok := tagOptions[key]; ok {
+			return fmt.Errorf("tag option %q requires value", key)
+		}
+		return fmt.Errorf("unknown tag option %q", key)
+	}
+
+	if _, ok := tagOptions[key]; !ok {
+		return fmt.Errorf("unknown tag option %q", key)
+	}
+	if value == "" {
+		return fmt.Errorf("tag option %q requires value", key)
+	}
+
+	switch key {
+	case "name":
+		finfo.name = value
+		return nil
+	case "prefix":
+		if finfo.flags&fForm == 0 {
+			return fmt.Errorf("tag option prefix is only supported by nested forms")
+		}
+		finfo.prefix = value
+		return nil
+	case "widget":
+		if _, ok := widgetConstrs[value]; !ok {
+			return fmt.Errorf("unknown widget %q", value)
+		}
+		finfo.widget = value
+		return nil
+	case "minlen", "maxlen", "minfiles", "maxfiles", "minwidth", "maxwidth", "minheight", "maxheight":
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("tag option %s=%s: value should be non-negative integer", key, value)
+		}
+	case "ratio":
+		if _, _, ok := parseRatio(value); !ok {
+			return fmt.Errorf("tag option ratio=%s: value should be width:height, e.g. 16:9", value)
+		}
+	case "minsize", "maxsize":
+		if _, ok := parseFileSize(value); !ok {
+			return fmt.Errorf("tag option %s=%s: value should be size such as 512KB or 5MB", key, value)
+		}
+	case "pattern":
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("tag option pattern=%s: %v", value, err)
+		}
+	}
+	finfo.opts = append(finfo.opts, fieldOption{key: key, value: value})
+	return nil
+}
+
+// splitTag splits tag by commas that are not escaped with backslash.
+func splitTag(tag string) []string {
+	tokens := make([]string, 0)
+	token := make([]byte, 0, len(tag))
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			token = append(token, ',')
+			i++
+		case c == ',':
+			tokens = append(tokens, string(token))
+			token = token[:0]
+		default:
+			token = append(token, c)
+		}
+	}
+	return append(tokens, string(token))
+}
+
+func (m *typeInfoMap) ModelInfo(typ reflect.Type) *modelInfo {
+	m.l.RLock()
+	minfo, ok := m.mm[typ]
+	m.l.RUnlock()
+	if ok {
+		return minfo
+	}
+
+	minfo = &modelInfo{
+		fields: make(map[string]*modelFieldInfo),
+	}
+	m.addModelFields(minfo, typ, nil)
+
+	m.l.Lock()
+	m.mm[typ] = minfo
+	m.l.Unlock()
+
+	return minfo
+}
+
+// addModelFields adds exported fields of typ to minfo. Fields of embedded
+// structs are added after own fields so the latter take precedence.
+func (m *typeInfoMap) addModelFields(minfo *modelInfo, typ reflect.Type, idx []int) {
+	var embedded []reflect.StructField
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, f)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("gforms"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := minfo.fields[name]; ok {
+			continue
+		}
+		minfo.fields[name] = &modelFieldInfo{
+			idx:  append(append([]int(nil), idx...), f.Index...),
+			name: f.Name,
+		}
+	}
+
+	for _, f := range embedded {
+		m.addModelFields(minfo, f.Type, append(append([]int(nil), idx...), f.Index...))
+	}
 }
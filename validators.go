@@ -1,7 +1,14 @@
 package gforms
 
 import (
-	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 type Validator interface {
@@ -15,14 +22,18 @@ type StringChoicesValidator struct {
 func (v *StringChoicesValidator) Validate(rawValue interface{}) error {
 	value, ok := rawValue.(string)
 	if !ok {
-		return fmt.Errorf("Type %T is not supported", rawValue)
+		return errUnsupportedType(rawValue)
 	}
 	for _, choice := range v.Choices {
 		if choice.Value == value {
 			return nil
 		}
 	}
-	return fmt.Errorf("%v is invalid choice", value)
+	return NewValidationError(
+		CodeInvalidChoice,
+		"{value} is invalid choice",
+		map[string]interface{}{"value": value},
+	)
 }
 
 func NewStringChoicesValidator(choices []StringChoice) *StringChoicesValidator {
@@ -36,16 +47,251 @@ type Int64ChoicesValidator struct {
 func (v *Int64ChoicesValidator) Validate(rawValue interface{}) error {
 	value, ok := rawValue.(int64)
 	if !ok {
-		return fmt.Errorf("Type %T is not supported", rawValue)
+		return errUnsupportedType(rawValue)
 	}
 	for _, choice := range v.Choices {
 		if choice.Value == value {
 			return nil
 		}
 	}
-	return fmt.Errorf("%v is invalid choice", value)
+	return NewValidationError(
+		CodeInvalidChoice,
+		"{value} is invalid choice",
+		map[string]interface{}{"value": value},
+	)
 }
 
 func NewInt64ChoicesValidator(choices []Int64Choice) *Int64ChoicesValidator {
 	return &Int64ChoicesValidator{Choices: choices}
 }
+
+// PatternValidator checks that whole string value matches regular
+// expression (the same way as HTML5 pattern attribute).
+type PatternValidator struct {
+	Pattern string
+	re      *regexp.Regexp
+}
+
+func (v *PatternValidator) Validate(rawValue interface{}) error {
+	value, ok := rawValue.(string)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+	if !v.re.MatchString(value) {
+		return NewValidationError(
+			CodePattern,
+			"This field should match pattern {pattern}",
+			map[string]interface{}{"pattern": v.Pattern},
+		)
+	}
+	return nil
+}
+
+func NewPatternValidator(pattern string) *PatternValidator {
+	return &PatternValidator{
+		Pattern: pattern,
+		re:      regexp.MustCompile("^(?:" + pattern + ")$"),
+	}
+}
+
+// MaxFileSizeValidator checks that uploaded file is not larger than Max
+// bytes. Error params are max (bytes), max_size (e.g. "5 MB") and size.
+type MaxFileSizeValidator struct {
+	Max int64
+}
+
+func (v *MaxFileSizeValidator) Validate(rawValue interface{}) error {
+	fh, ok := rawValue.(*multipart.FileHeader)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+	if fh.Size > v.Max {
+		return NewValidationError(
+			CodeMaxFileSize,
+			"File size should be at most {max_size}",
+			map[string]interface{}{
+				"max":      v.Max,
+				"max_size": formatFileSize(v.Max),
+				"size":     fh.Size,
+			},
+		)
+	}
+	return nil
+}
+
+func NewMaxFileSizeValidator(max int64) *MaxFileSizeValidator {
+	return &MaxFileSizeValidator{Max: max}
+}
+
+// MinFileSizeValidator checks that uploaded file is at least Min bytes.
+// Error params are min (bytes), min_size (e.g. "1 KB") and size.
+type MinFileSizeValidator struct {
+	Min int64
+}
+
+func (v *MinFileSizeValidator) Validate(rawValue interface{}) error {
+	fh, ok := rawValue.(*multipart.FileHeader)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+	if fh.Size < v.Min {
+		return NewValidationError(
+			CodeMinFileSize,
+			"File size should be at least {min_size}",
+			map[string]interface{}{
+				"min":      v.Min,
+				"min_size": formatFileSize(v.Min),
+				"size":     fh.Size,
+			},
+		)
+	}
+	return nil
+}
+
+func NewMinFileSizeValidator(min int64) *MinFileSizeValidator {
+	return &MinFileSizeValidator{Min: min}
+}
+
+// FileExtensionValidator checks extension of uploaded file name.
+// Extensions are compared case-insensitively and may be given with or
+// without leading dot.
+type FileExtensionValidator struct {
+	Extensions []string
+}
+
+func (v *FileExtensionValidator) Validate(rawValue interface{}) error {
+	fh, ok := rawValue.(*multipart.FileHeader)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(fh.Filename), "."))
+	for _, allowed := range v.Extensions {
+		if ext != "" && ext == strings.ToLower(strings.TrimPrefix(allowed, ".")) {
+			return nil
+		}
+	}
+	return NewValidationError(
+		CodeFileExtension,
+		"File extension {extension} is not allowed (allowed: {allowed})",
+		map[string]interface{}{
+			"extension": ext,
+			"allowed":   strings.Join(v.Extensions, ", "),
+		},
+	)
+}
+
+// accept returns extensions in format of HTML accept attribute.
+func (v *FileExtensionValidator) accept() []string {
+	accept := make([]string, 0, len(v.Extensions))
+	for _, ext := range v.Extensions {
+		accept = append(accept, "."+strings.TrimPrefix(ext, "."))
+	}
+	return accept
+}
+
+func NewFileExtensionValidator(extensions ...string) *FileExtensionValidator {
+	return &FileExtensionValidator{Extensions: extensions}
+}
+
+// errReadFile hides errors of reading uploaded file, which may contain
+// temporary file paths, from users.
+var errReadFile = NewValidationError(CodeReadFile, "Could not read uploaded file", nil)
+
+// FileTypeValidator checks MIME type of uploaded file sniffed from its
+// first 512 bytes with http.DetectContentType; Content-Type sent by the
+// client is ignored. Types may use wildcard subtype, e.g. "image/*".
+type FileTypeValidator struct {
+	Types []string
+}
+
+func (v *FileTypeValidator) Validate(rawValue interface{}) error {
+	fh, ok := rawValue.(*multipart.FileHeader)
+	if !ok {
+		return errUnsupportedType(rawValue)
+	}
+	typ, err := DetectFileType(fh)
+	if err != nil {
+		return errReadFile
+	}
+	for _, allowed := range v.Types {
+		if matchMIMEType(allowed, typ) {
+			return nil
+		}
+	}
+	return NewValidationError(
+		CodeFileType,
+		"File type {type} is not allowed (allowed: {allowed})",
+		map[string]interface{}{
+			"type":    typ,
+			"allowed": strings.Join(v.Types, ", "),
+		},
+	)
+}
+
+func NewFileTypeValidator(types ...string) *FileTypeValidator {
+	return &FileTypeValidator{Types: types}
+}
+
+// DetectFileType returns MIME type (without parameters) of uploaded file
+// sniffed from its content.
+func DetectFileType(fh *multipart.FileHeader) (string, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	typ := http.DetectContentType(buf[:n])
+	if i := strings.IndexByte(typ, ';'); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.TrimSpace(typ), nil
+}
+
+func matchMIMEType(pattern, typ string) bool {
+	pattern = strings.ToLower(pattern)
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(typ, prefix+"/")
+	}
+	return pattern == typ
+}
+
+var fileSizeUnits = []string{"bytes", "KB", "MB", "GB", "TB"}
+
+// formatFileSize formats size in bytes using binary units, e.g. "5 MB"
+// or "1.5 KB".
+func formatFileSize(size int64) string {
+	n := float64(size)
+	i := 0
+	for n >= 1024 && i < len(fileSizeUnits)-1 {
+		n /= 1024
+		i++
+	}
+	n = math.Round(n*10) / 10
+	return strconv.FormatFloat(n, 'f', -1, 64) + " " + fileSizeUnits[i]
+}
+
+// parseFileSize parses size such as "512", "100KB" or "5MB" (binary
+// units).
+func parseFileSize(s string) (int64, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for i, unit := range []string{"KB", "MB", "GB", "TB"} {
+		if num, ok := strings.CutSuffix(s, unit); ok {
+			s = strings.TrimSpace(num)
+			mult = 1 << (10 * uint(i+1))
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "B")
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/mult {
+		return 0, false
+	}
+	return n * mult, true
+}
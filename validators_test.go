@@ -0,0 +1,135 @@
+package gforms_test
+
+import (
+	"bytes"
+	"html/template"
+	"mime/multipart"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type ValidatorsTest struct{}
+
+var _ = Suite(&ValidatorsTest{})
+
+//------------------------------------------------------------------------------
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// newMultipartForm returns multipart form with files given as
+// field -> filename -> content.
+func newMultipartForm(c *C, files map[string]map[string][]byte) *multipart.Form {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for field, named := range files {
+		for name, content := range named {
+			fw, err := w.CreateFormFile(field, name)
+			c.Assert(err, IsNil)
+			_, err = fw.Write(content)
+			c.Assert(err, IsNil)
+		}
+	}
+	c.Assert(w.Close(), IsNil)
+
+	form, err := multipart.NewReader(buf, w.Boundary()).ReadForm(1 << 20)
+	c.Assert(err, IsNil)
+	return form
+}
+
+func newFileHeader(c *C, name string, content []byte) *multipart.FileHeader {
+	form := newMultipartForm(c, map[string]map[string][]byte{"file": {name: content}})
+	return form.File["file"][0]
+}
+
+func (t *ValidatorsTest) TestFileSize(c *C) {
+	fh := newFileHeader(c, "a.txt", bytes.Repeat([]byte("a"), 2048))
+
+	c.Assert(gforms.NewMaxFileSizeValidator(2048).Validate(fh), IsNil)
+	err := gforms.NewMaxFileSizeValidator(1024).Validate(fh)
+	c.Assert(err, ErrorMatches, "File size should be at most 1 KB")
+	verr := err.(*gforms.ValidationError)
+	c.Assert(verr.Code, Equals, gforms.CodeMaxFileSize)
+	c.Assert(verr.Params["max"], Equals, int64(1024))
+	c.Assert(verr.Params["size"], Equals, int64(2048))
+
+	c.Assert(gforms.NewMinFileSizeValidator(2048).Validate(fh), IsNil)
+	err = gforms.NewMinFileSizeValidator(5 << 20).Validate(fh)
+	c.Assert(err, ErrorMatches, "File size should be at least 5 MB")
+	c.Assert(err.(*gforms.ValidationError).Code, Equals, gforms.CodeMinFileSize)
+}
+
+func (t *ValidatorsTest) TestFileExtension(c *C) {
+	v := gforms.NewFileExtensionValidator("jpg", ".png")
+
+	c.Assert(v.Validate(newFileHeader(c, "a.PNG", nil)), IsNil)
+	c.Assert(v.Validate(newFileHeader(c, "a.jpg", nil)), IsNil)
+	err := v.Validate(newFileHeader(c, "a.png.exe", nil))
+	c.Assert(err, ErrorMatches, `File extension exe is not allowed \(allowed: jpg, .png\)`)
+	c.Assert(err.(*gforms.ValidationError).Code, Equals, gforms.CodeFileExtension)
+	c.Assert(v.Validate(newFileHeader(c, "png", nil)), NotNil)
+}
+
+func (t *ValidatorsTest) TestFileType(c *C) {
+	png := newFileHeader(c, "a.txt", pngHeader)
+	text := newFileHeader(c, "a.png", []byte("hello"))
+
+	typ, err := gforms.DetectFileType(png)
+	c.Assert(err, IsNil)
+	c.Assert(typ, Equals, "image/png")
+
+	c.Assert(gforms.NewFileTypeValidator("image/png").Validate(png), IsNil)
+	c.Assert(gforms.NewFileTypeValidator("image/*").Validate(png), IsNil)
+	err = gforms.NewFileTypeValidator("image/*").Validate(text)
+	c.Assert(err, ErrorMatches, `File type text/plain is not allowed \(allowed: image/\*\)`)
+	c.Assert(err.(*gforms.ValidationError).Params["type"], Equals, "text/plain")
+
+	// Zero FileHeader can't be opened.
+	err = gforms.NewFileTypeValidator("image/*").Validate(&multipart.FileHeader{Filename: "a.png"})
+	c.Assert(err, ErrorMatches, "Could not read uploaded file")
+	c.Assert(err.(*gforms.ValidationError).Code, Equals, gforms.CodeReadFile)
+}
+
+type UploadForm struct {
+	*gforms.BaseForm
+	Avatar *gforms.FileField `gforms:",required,maxsize=1KB,extensions=png|gif,filetypes=image/*"`
+}
+
+func (t *ValidatorsTest) TestFileTagOptions(c *C) {
+	f := &UploadForm{BaseForm: &gforms.BaseForm{}}
+	c.Assert(gforms.InitForm(f), IsNil)
+	c.Assert(f.Avatar.Render(), Equals, template.HTML(
+		`<input type="file" id="Avatar" name="Avatar" required="required" accept=".png,.gif,image/*" />`,
+	))
+
+	form := newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.png": pngHeader}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, true)
+	c.Assert(f.Avatar.Value().Filename, Equals, "a.png")
+
+	form = newMultipartForm(c, map[string]map[string][]byte{"Avatar": {"a.txt": bytes.Repeat([]byte("a"), 2048)}})
+	c.Assert(gforms.IsMultipartFormValid(f, form), Equals, false)
+	codes := make([]string, 0)
+	for _, err := range f.Errors()["Avatar"] {
+		codes = append(codes, err.(*gforms.ValidationError).Code)
+	}
+	c.Assert(codes, DeepEquals, []string{gforms.CodeMaxFileSize, gforms.CodeFileExtension, gforms.CodeFileType})
+}
+
+type BadUploadForm struct {
+	*gforms.BaseForm
+	Avatar *gforms.FileField `gforms:",maxsize=5XB"`
+}
+
+type BadSizeForm struct {
+	*gforms.BaseForm
+	Name *gforms.StringField `gforms:",maxsize=5MB"`
+}
+
+func (t *ValidatorsTest) TestFileTagOptionErrors(c *C) {
+	err := gforms.InitForm(&BadUploadForm{BaseForm: &gforms.BaseForm{}})
+	c.Assert(err, ErrorMatches, `.*tag option maxsize=5XB: value should be size such as 512KB or 5MB`)
+
+	err = gforms.InitForm(&BadSizeForm{BaseForm: &gforms.BaseForm{}})
+	c.Assert(err, ErrorMatches, `.*tag option "maxsize" is not supported by \*gforms.StringField`)
+}
@@ -35,9 +35,18 @@ func (w *BaseWidget) Attrs() *WidgetAttrs {
 	return w.attrs
 }
 
+// renderAttrs returns copy of widget attributes updated with attrs.
+// Widget attributes are never modified by Render, so one widget can be
+// rendered concurrently.
+func (w *BaseWidget) renderAttrs(attrs []string) *WidgetAttrs {
+	wAttrs := w.Attrs().Clone()
+	wAttrs.FromSlice(attrs)
+	return wAttrs
+}
+
 func (w *BaseWidget) Render(attrs []string, values ...string) template.HTML {
-	w.Attrs().FromSlice(attrs)
-	html := fmt.Sprintf(w.HTML, w.Attrs().String(), tTemplate.HTMLEscapeString(values[0]))
+	wAttrs := w.renderAttrs(attrs)
+	html := fmt.Sprintf(w.HTML, wAttrs.String(), tTemplate.HTMLEscapeString(values[0]))
 	return template.HTML(html)
 }
 
@@ -62,8 +71,9 @@ func (w *HiddenWidget) IsHidden() bool {
 }
 
 func (w *HiddenWidget) Render(attrs []string, values ...string) template.HTML {
-	w.attrs.Set("value", values[0])
-	s := `<input` + w.attrs.String() + ` />`
+	wAttrs := w.renderAttrs(attrs)
+	wAttrs.Set("value", values[0])
+	s := `<input` + wAttrs.String() + ` />`
 	return template.HTML(s)
 }
 
@@ -86,6 +96,74 @@ func NewTextWidget() *TextWidget {
 
 //------------------------------------------------------------------------------
 
+type NumberWidget struct {
+	*BaseWidget
+}
+
+func NewNumberWidget() *NumberWidget {
+	return &NumberWidget{
+		&BaseWidget{
+			HTML: `<input%v value="%v" />`,
+			attrs: &WidgetAttrs{
+				attrs: [][2]string{{"type", "number"}},
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type DateWidget struct {
+	*BaseWidget
+}
+
+func NewDateWidget() *DateWidget {
+	return &DateWidget{
+		&BaseWidget{
+			HTML: `<input%v value="%v" />`,
+			attrs: &WidgetAttrs{
+				attrs: [][2]string{{"type", "date"}},
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type TimeWidget struct {
+	*BaseWidget
+}
+
+func NewTimeWidget() *TimeWidget {
+	return &TimeWidget{
+		&BaseWidget{
+			HTML: `<input%v value="%v" />`,
+			attrs: &WidgetAttrs{
+				attrs: [][2]string{{"type", "time"}},
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type DateTimeWidget struct {
+	*BaseWidget
+}
+
+func NewDateTimeWidget() *DateTimeWidget {
+	return &DateTimeWidget{
+		&BaseWidget{
+			HTML: `<input%v value="%v" />`,
+			attrs: &WidgetAttrs{
+				attrs: [][2]string{{"type", "datetime-local"}},
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
 type TextareaWidget struct {
 	*BaseWidget
 }
@@ -136,7 +214,7 @@ func (w *SelectWidget) Options(selValues ...string) []string {
 		label := tTemplate.HTMLEscapeString(choice[1])
 		attrs := ""
 		for _, selValue := range selValues {
-			if value == selValue {
+			if choice[0] == selValue {
 				attrs = ` selected="selected"`
 			}
 		}
@@ -147,9 +225,9 @@ func (w *SelectWidget) Options(selValues ...string) []string {
 }
 
 func (w *SelectWidget) Render(attrs []string, values ...string) template.HTML {
-	w.Attrs().FromSlice(attrs)
+	wAttrs := w.renderAttrs(attrs)
 	options := strings.Join(w.Options(values...), "\n")
-	selectHTML := fmt.Sprintf(w.HTML, w.Attrs().String(), options)
+	selectHTML := fmt.Sprintf(w.HTML, wAttrs.String(), options)
 	return template.HTML(selectHTML)
 }
 
@@ -177,45 +255,85 @@ func NewMultiSelectWidget() *SelectWidget {
 
 //------------------------------------------------------------------------------
 
-type RadioWidget struct {
-	*BaseWidget
-	choices [][2]string
+func choiceId(id string, i int) string {
+	return fmt.Sprintf("%v_%v", id, i)
 }
 
-func (w *RadioWidget) SetChoices(choices [][2]string) {
-	w.choices = choices
+func isChecked(value string, checkedValues []string) bool {
+	for _, checkedValue := range checkedValues {
+		if value == checkedValue {
+			return true
+		}
+	}
+	return false
 }
 
-func (w *RadioWidget) Radios(attrs []string, checkedValue string) []template.HTML {
-	id, _ := w.Attrs().Get("id")
-	radios := make([]template.HTML, 0, len(w.choices))
-	for i, choice := range w.choices {
-		wAttrs := w.Attrs().Clone()
-		wAttrs.Set("id", fmt.Sprintf("%v_%v", id, i))
-		wAttrs.FromSlice(attrs)
+// choiceInputs renders one input per choice. Every input gets id
+// "<widget id>_<choice index>". When labelled is true input is wrapped
+// into label that refers to that id.
+func choiceInputs(
+	wAttrs *WidgetAttrs,
+	choices [][2]string,
+	attrs []string,
+	checkedValues []string,
+	labelled bool,
+) []template.HTML {
+	id, _ := wAttrs.Get("id")
+	inputs := make([]template.HTML, 0, len(choices))
+	for i, choice := range choices {
+		cAttrs := wAttrs.Clone()
+		cAttrs.Set("id", choiceId(id, i))
+		cAttrs.FromSlice(attrs)
 
 		value := tTemplate.HTMLEscapeString(choice[0])
 		label := tTemplate.HTMLEscapeString(choice[1])
 
 		checked := ""
-		if value == checkedValue {
+		if isChecked(choice[0], checkedValues) {
 			checked = ` checked="checked"`
 		}
 
-		radio := fmt.Sprintf(
+		input := fmt.Sprintf(
 			`<input%v value="%v"%v /> %v`,
-			wAttrs.String(),
+			cAttrs.String(),
 			value,
 			checked,
 			label)
-		radios = append(radios, template.HTML(radio))
+		if labelled {
+			cId, _ := cAttrs.Get("id")
+			input = fmt.Sprintf(`<label for="%v">%v</label>`, tTemplate.HTMLEscapeString(cId), input)
+		}
+		inputs = append(inputs, template.HTML(input))
 	}
-	return radios
+	return inputs
+}
+
+func joinHTML(htmls []template.HTML, sep string) template.HTML {
+	ss := make([]string, 0, len(htmls))
+	for _, html := range htmls {
+		ss = append(ss, string(html))
+	}
+	return template.HTML(strings.Join(ss, sep))
+}
+
+//------------------------------------------------------------------------------
+
+type RadioWidget struct {
+	*BaseWidget
+	choices [][2]string
+}
+
+func (w *RadioWidget) SetChoices(choices [][2]string) {
+	w.choices = choices
+}
+
+func (w *RadioWidget) Radios(attrs []string, checkedValue string) []template.HTML {
+	return choiceInputs(w.Attrs(), w.choices, attrs, []string{checkedValue}, false)
 }
 
 func (w *RadioWidget) Render(attrs []string, checkedValues ...string) template.HTML {
-	panic("not implemented.")
-	return template.HTML("")
+	radios := choiceInputs(w.Attrs(), w.choices, attrs, checkedValues, true)
+	return joinHTML(radios, "\n")
 }
 
 func NewRadioWidget() *RadioWidget {
@@ -230,6 +348,36 @@ func NewRadioWidget() *RadioWidget {
 
 //------------------------------------------------------------------------------
 
+type CheckboxGroupWidget struct {
+	*BaseWidget
+	choices [][2]string
+}
+
+func (w *CheckboxGroupWidget) SetChoices(choices [][2]string) {
+	w.choices = choices
+}
+
+func (w *CheckboxGroupWidget) Checkboxes(attrs []string, checkedValues ...string) []template.HTML {
+	return choiceInputs(w.Attrs(), w.choices, attrs, checkedValues, false)
+}
+
+func (w *CheckboxGroupWidget) Render(attrs []string, checkedValues ...string) template.HTML {
+	checkboxes := choiceInputs(w.Attrs(), w.choices, attrs, checkedValues, true)
+	return joinHTML(checkboxes, "\n")
+}
+
+func NewCheckboxGroupWidget() *CheckboxGroupWidget {
+	return &CheckboxGroupWidget{
+		BaseWidget: &BaseWidget{
+			attrs: &WidgetAttrs{
+				attrs: [][2]string{{"type", "checkbox"}},
+			},
+		},
+	}
+}
+
+//------------------------------------------------------------------------------
+
 type FileWidget struct {
 	*BaseWidget
 }
@@ -245,8 +393,14 @@ func NewFileWidget() *FileWidget {
 	}
 }
 
+// NewMultiFileWidget returns file input that accepts several files.
+func NewMultiFileWidget() *FileWidget {
+	w := NewFileWidget()
+	w.attrs.Set("multiple", "multiple")
+	return w
+}
+
 func (w *FileWidget) Render(attrs []string, values ...string) template.HTML {
-	w.Attrs().FromSlice(attrs)
-	html := fmt.Sprintf(w.HTML, w.Attrs().String())
+	html := fmt.Sprintf(w.HTML, w.renderAttrs(attrs).String())
 	return template.HTML(html)
 }
@@ -1,7 +1,9 @@
 package gforms_test
 
 import (
+	"fmt"
 	"html/template"
+	"sync"
 
 	. "launchpad.net/gocheck"
 
@@ -37,3 +39,87 @@ func (t *WidgetsTest) TestWidgets(c *C) {
 		c.Assert(tt.given, Equals, tt.expected)
 	}
 }
+
+func (t *WidgetsTest) TestRadioWidget(c *C) {
+	w := gforms.NewRadioWidget()
+	w.Attrs().Set("id", "foo")
+	w.Attrs().Set("name", "foo")
+	w.SetChoices([][2]string{{"1", "One"}, {"2", "Two"}})
+
+	c.Assert(w.Render(nil, "2"), Equals, template.HTML(
+		`<label for="foo_0"><input type="radio" id="foo_0" name="foo" value="1" /> One</label>`+"\n"+
+			`<label for="foo_1"><input type="radio" id="foo_1" name="foo" value="2" checked="checked" /> Two</label>`,
+	))
+
+	id, _ := w.Attrs().Get("id")
+	c.Assert(id, Equals, "foo")
+}
+
+func (t *WidgetsTest) TestCheckboxGroupWidget(c *C) {
+	w := gforms.NewCheckboxGroupWidget()
+	w.Attrs().Set("id", "foo")
+	w.Attrs().Set("name", "foo")
+	w.SetChoices([][2]string{{"1", "One"}, {"2", "Two"}, {"3", "Three"}})
+
+	c.Assert(w.Render([]string{"class", "bar"}, "1", "3"), Equals, template.HTML(
+		`<label for="foo_0"><input type="checkbox" id="foo_0" name="foo" class="bar" value="1" checked="checked" /> One</label>`+"\n"+
+			`<label for="foo_1"><input type="checkbox" id="foo_1" name="foo" class="bar" value="2" /> Two</label>`+"\n"+
+			`<label for="foo_2"><input type="checkbox" id="foo_2" name="foo" class="bar" value="3" checked="checked" /> Three</label>`,
+	))
+}
+
+func (t *WidgetsTest) TestRenderDoesNotChangeWidget(c *C) {
+	f := gforms.NewSelectStringField()
+	f.SetName("lang")
+	f.SetChoices([]gforms.StringChoice{{"go", "Golang"}})
+
+	c.Assert(f.Render("class", "foo"), Equals, template.HTML(
+		`<select id="lang" name="lang" class="foo"><option value="go">Golang</option></select>`,
+	))
+	c.Assert(f.Render(), Equals, template.HTML(
+		`<select id="lang" name="lang"><option value="go">Golang</option></select>`,
+	))
+
+	hidden := gforms.NewHiddenWidget()
+	c.Assert(hidden.Render([]string{"name", "token"}, "foo"), Equals, template.HTML(
+		`<input type="hidden" name="token" value="foo" />`,
+	))
+	c.Assert(hidden.Attrs().Names(), DeepEquals, []string{"type"})
+}
+
+func (t *WidgetsTest) TestCloneDoesNotShareAttrs(c *C) {
+	attrs := &gforms.WidgetAttrs{}
+	attrs.Set("id", "foo")
+
+	clone := attrs.Clone()
+	clone.Set("id", "bar")
+
+	id, _ := attrs.Get("id")
+	c.Assert(id, Equals, "foo")
+}
+
+func (t *WidgetsTest) TestConcurrentRender(c *C) {
+	fields := []gforms.Field{
+		gforms.NewStringField(),
+		gforms.NewBoolField(),
+		gforms.NewFileField(),
+		gforms.NewRadioStringField(),
+	}
+
+	for _, f := range fields {
+		f.SetName("foo")
+		expected := f.Render()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				f.Render("class", fmt.Sprint("class", i))
+			}(i)
+		}
+		wg.Wait()
+
+		c.Assert(f.Render(), Equals, expected)
+	}
+}
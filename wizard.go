@@ -0,0 +1,372 @@
+package gforms
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultWizardMaxAge is default lifetime of the wizard state.
+const DefaultWizardMaxAge = 24 * time.Hour
+
+var (
+	ErrWizardState   = errors.New("gforms: wizard state is invalid")
+	ErrWizardExpired = errors.New("gforms: wizard state has expired")
+)
+
+// WizardStorage keeps wizard state on the server. Only signed state id
+// is passed through the client then.
+type WizardStorage interface {
+	Load(id string) ([]byte, error)
+	Save(id string, data []byte) error
+	Delete(id string) error
+}
+
+// Wizard is a form that spans several pages (steps). Only the form of
+// the current step is validated; cleaned values of completed steps are
+// carried in the encrypted hidden state field (or in Storage, and then
+// the field holds only signed state id) so user can go back without
+// losing data. Steps are validated once more after the
+// last step and passed to Done. Steps can't contain file fields, because
+// uploaded files are not kept between requests.
+//
+// Typical usage:
+//
+//	w := gforms.NewWizard(signer, newAccountForm, newProfileForm)
+//	w.Done = func(forms []gforms.Form) error { ... }
+//	if r.Method == "POST" {
+//		done, err := w.Process(r.PostForm)
+//		...
+//	} else {
+//		err = w.Start()
+//	}
+//	// render w.StateField() and w.Form()
+type Wizard struct {
+	// Prefix is used to name wizard fields: "<prefix>-state" and
+	// "<prefix>-back" (back button). Default is "wizard".
+	Prefix string
+	Signer *Signer
+	// Storage keeps state on the server when set.
+	Storage WizardStorage
+	// MaxAge limits time between steps. State is reissued on every
+	// step. Default is DefaultWizardMaxAge.
+	MaxAge time.Duration
+	// Done is called with forms of all steps after the last step.
+	Done func(forms []Form) error
+
+	steps []func() Form
+
+	state wizardState
+	form  Form
+	field *StringField
+}
+
+type wizardState struct {
+	ID     string       `json:"id,omitempty"`
+	Step   int          `json:"step"`
+	Data   []url.Values `json:"data"`
+	Issued time.Time    `json:"issued"`
+}
+
+// NewWizard returns wizard with given steps. Like in FormSet, steps
+// should return new forms that are not initialized yet.
+func NewWizard(signer *Signer, steps ...func() Form) *Wizard {
+	field := NewStringField()
+	field.SetWidget(NewHiddenWidget())
+	return &Wizard{
+		Prefix: "wizard",
+		Signer: signer,
+		steps:  steps,
+		field:  field,
+	}
+}
+
+// Step returns index of the current step.
+func (w *Wizard) Step() int {
+	return w.state.Step
+}
+
+func (w *Wizard) StepCount() int {
+	return len(w.steps)
+}
+
+// Form returns form of the current step.
+func (w *Wizard) Form() Form {
+	return w.form
+}
+
+// StateField returns hidden field with signed wizard state that should
+// be rendered together with the form of the current step.
+func (w *Wizard) StateField() *StringField {
+	return w.field
+}
+
+// BackName returns name of the submit button that moves wizard to the
+// previous step.
+func (w *Wizard) BackName() string {
+	return w.Prefix + "-back"
+}
+
+// Start resets wizard to the first step. It returns error when form of
+// any step can't be initialized or has file fields.
+func (w *Wizard) Start() error {
+	for i := range w.steps {
+		if _, err := w.newStepForm(i); err != nil {
+			return err
+		}
+	}
+
+	w.state = wizardState{
+		Data: make([]url.Values, len(w.steps)),
+	}
+	if w.Storage != nil {
+		id, err := newWizardID()
+		if err != nil {
+			return err
+		}
+		w.state.ID = id
+	}
+	return w.show()
+}
+
+// Process restores wizard state from values and handles the current
+// step: it moves back when back button is submitted, otherwise validates
+// the step and moves forward. After the last step Done is called and
+// Process returns true. Tampered or missing state is reported as
+// ErrWizardState and state older than MaxAge as ErrWizardExpired.
+func (w *Wizard) Process(values url.Values) (bool, error) {
+	if err := w.load(values.Get(w.stateName())); err != nil {
+		return false, err
+	}
+
+	form, err := w.newStepForm(w.state.Step)
+	if err != nil {
+		return false, err
+	}
+	w.form = form
+	stepValues := formValues(form, values)
+
+	if _, ok := values[w.BackName()]; ok && w.state.Step > 0 {
+		w.state.Data[w.state.Step] = stepValues
+		w.state.Step--
+		return false, w.show()
+	}
+
+	if !IsFormValid(form, values) {
+		return false, w.save()
+	}
+	w.state.Data[w.state.Step] = cleanedValues(form, stepValues)
+	if w.state.Step < len(w.steps)-1 {
+		w.state.Step++
+		return false, w.show()
+	}
+
+	forms := make([]Form, len(w.steps))
+	for i := range w.steps {
+		form, err := w.newStepForm(i)
+		if err != nil {
+			return false, err
+		}
+		if !IsFormValid(form, w.state.Data[i]) {
+			w.state.Step = i
+			w.form = form
+			return false, w.save()
+		}
+		forms[i] = form
+	}
+	if w.Done != nil {
+		if err := w.Done(forms); err != nil {
+			return false, err
+		}
+	}
+	if w.Storage != nil {
+		return true, w.Storage.Delete(w.state.ID)
+	}
+	return true, nil
+}
+
+// show creates form of the current step filled with previously
+// submitted values and saves state.
+func (w *Wizard) show() error {
+	form, err := w.newStepForm(w.state.Step)
+	if err != nil {
+		return err
+	}
+	if values := w.state.Data[w.state.Step]; values != nil {
+		IsFormValid(form, values)
+		form.SetErrors(nil)
+		for _, f := range form.FieldList() {
+			f.SetValidationError(nil)
+		}
+	}
+	w.form = form
+	return w.save()
+}
+
+func (w *Wizard) newStepForm(i int) (Form, error) {
+	form := w.steps[i]()
+	if err := InitForm(form); err != nil {
+		return nil, err
+	}
+	for _, f := range form.FieldList() {
+		if f.IsMultipart() {
+			return nil, fmt.Errorf("gforms: wizard step %d: field %s: file fields are not supported", i, f.Name())
+		}
+	}
+	return form, nil
+}
+
+func (w *Wizard) stateName() string {
+	return w.Prefix + "-state"
+}
+
+func (w *Wizard) maxAge() time.Duration {
+	if w.MaxAge != 0 {
+		return w.MaxAge
+	}
+	return DefaultWizardMaxAge
+}
+
+func (w *Wizard) save() error {
+	w.state.Issued = time.Now()
+	b, err := json.Marshal(&w.state)
+	if err != nil {
+		return err
+	}
+	w.field.SetName(w.stateName())
+	if w.Storage != nil {
+		if err := w.Storage.Save(w.state.ID, b); err != nil {
+			return err
+		}
+		w.field.SetInitial(w.Signer.Sign([]byte(w.Prefix + ":" + w.state.ID)))
+		return nil
+	}
+	w.field.SetInitial(w.Signer.Encrypt(append([]byte(w.Prefix+":"), b...)))
+	return nil
+}
+
+func (w *Wizard) load(value string) error {
+	var b []byte
+	var err error
+	if w.Storage != nil {
+		b, err = w.Signer.Verify(value)
+	} else {
+		b, err = w.Signer.Decrypt(value)
+	}
+	if err != nil {
+		return ErrWizardState
+	}
+	prefix := []byte(w.Prefix + ":")
+	if len(b) < len(prefix) || string(b[:len(prefix)]) != string(prefix) {
+		return ErrWizardState
+	}
+	b = b[len(prefix):]
+
+	if w.Storage != nil {
+		id := string(b)
+		if b, err = w.Storage.Load(id); err != nil {
+			return err
+		}
+	}
+
+	var state wizardState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return ErrWizardState
+	}
+	if state.Step < 0 || state.Step >= len(w.steps) || len(state.Data) != len(w.steps) {
+		return ErrWizardState
+	}
+	if time.Since(state.Issued) > w.maxAge() {
+		return ErrWizardExpired
+	}
+	w.state = state
+	return nil
+}
+
+// formValues returns values of form fields.
+func formValues(form Form, values url.Values) url.Values {
+	fvalues := make(url.Values)
+	for _, f := range form.FieldList() {
+		if v, ok := values[f.Name()]; ok {
+			fvalues[f.Name()] = v
+		}
+	}
+	return fvalues
+}
+
+// cleanedValues returns cleaned values of valid form fields formatted
+// as strings, e.g. trimmed and normalized. Submitted values are used for
+// fields that don't format their values.
+func cleanedValues(form Form, values url.Values) url.Values {
+	cleaned := make(url.Values)
+	for _, f := range form.FieldList() {
+		switch field := f.(type) {
+		case SingleValueField:
+			if v := field.StringValue(); v != "" {
+				cleaned.Set(f.Name(), v)
+				continue
+			}
+		case MultiValueField:
+			if v := field.StringValue(); len(v) > 0 {
+				cleaned[f.Name()] = v
+				continue
+			}
+		}
+		if v, ok := values[f.Name()]; ok {
+			cleaned[f.Name()] = v
+		}
+	}
+	return cleaned
+}
+
+func newWizardID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+//------------------------------------------------------------------------------
+
+// MemoryWizardStorage keeps wizard state in memory. It is useful for
+// tests and single process servers.
+type MemoryWizardStorage struct {
+	l sync.RWMutex
+	m map[string][]byte
+}
+
+func NewMemoryWizardStorage() *MemoryWizardStorage {
+	return &MemoryWizardStorage{
+		m: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryWizardStorage) Load(id string) ([]byte, error) {
+	s.l.RLock()
+	defer s.l.RUnlock()
+	b, ok := s.m[id]
+	if !ok {
+		return nil, ErrWizardState
+	}
+	return b, nil
+}
+
+func (s *MemoryWizardStorage) Save(id string, data []byte) error {
+	s.l.Lock()
+	s.m[id] = data
+	s.l.Unlock()
+	return nil
+}
+
+func (s *MemoryWizardStorage) Delete(id string) error {
+	s.l.Lock()
+	delete(s.m, id)
+	s.l.Unlock()
+	return nil
+}
@@ -0,0 +1,170 @@
+package gforms_test
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"github.com/vmihailenco/gforms"
+)
+
+type WizardTest struct{}
+
+var _ = Suite(&WizardTest{})
+
+//------------------------------------------------------------------------------
+
+type AccountStepForm struct {
+	*gforms.BaseForm
+	Email *gforms.EmailField `gforms:",required"`
+}
+
+type ProfileStepForm struct {
+	*gforms.BaseForm
+	Name *gforms.StringField `gforms:",required"`
+	Age  *gforms.Int64Field
+}
+
+func NewTestWizard(storage gforms.WizardStorage, done *[]gforms.Form) *gforms.Wizard {
+	w := gforms.NewWizard(
+		gforms.NewSigner([]byte("secret")),
+		func() gforms.Form { return &AccountStepForm{BaseForm: &gforms.BaseForm{}} },
+		func() gforms.Form { return &ProfileStepForm{BaseForm: &gforms.BaseForm{}} },
+	)
+	w.Storage = storage
+	w.Done = func(forms []gforms.Form) error {
+		*done = forms
+		return nil
+	}
+	return w
+}
+
+// submit emulates next request: the wizard is created again and state is
+// taken from the rendered hidden field.
+func submit(w *gforms.Wizard, newWizard func() *gforms.Wizard, values url.Values) (*gforms.Wizard, bool, error) {
+	values.Set(w.StateField().Name(), w.StateField().Value())
+	w = newWizard()
+	done, err := w.Process(values)
+	return w, done, err
+}
+
+func (t *WizardTest) testSteps(c *C, storage gforms.WizardStorage) {
+	var forms []gforms.Form
+	newWizard := func() *gforms.Wizard { return NewTestWizard(storage, &forms) }
+
+	w := newWizard()
+	c.Assert(w.Start(), IsNil)
+	c.Assert(w.Step(), Equals, 0)
+	c.Assert(w.StateField().Name(), Equals, "wizard-state")
+
+	w, done, err := submit(w, newWizard, url.Values{})
+	c.Assert(err, IsNil)
+	c.Assert(done, Equals, false)
+	c.Assert(w.Step(), Equals, 0)
+	c.Assert(w.Form().Errors()["Email"][0], Equals, gforms.ErrRequired)
+
+	w, done, err = submit(w, newWizard, url.Values{"Email": {"foo@example.com"}})
+	c.Assert(err, IsNil)
+	c.Assert(done, Equals, false)
+	c.Assert(w.Step(), Equals, 1)
+	c.Assert(w.Form().(*ProfileStepForm).Name.Value(), Equals, "")
+
+	// Back keeps data of both steps.
+	w, done, err = submit(w, newWizard, url.Values{"Name": {"Bob"}, "wizard-back": {"1"}})
+	c.Assert(err, IsNil)
+	c.Assert(done, Equals, false)
+	c.Assert(w.Step(), Equals, 0)
+	c.Assert(w.Form().(*AccountStepForm).Email.Value(), Equals, "foo@example.com")
+
+	w, _, err = submit(w, newWizard, url.Values{"Email": {"bar@example.com"}})
+	c.Assert(err, IsNil)
+	c.Assert(w.Step(), Equals, 1)
+	c.Assert(w.Form().(*ProfileStepForm).Name.Value(), Equals, "Bob")
+	c.Assert(w.Form().Errors(), HasLen, 0)
+	c.Assert(forms, IsNil)
+
+	w, done, err = submit(w, newWizard, url.Values{"Name": {"Bob"}, "Age": {"42"}})
+	c.Assert(err, IsNil)
+	c.Assert(done, Equals, true)
+	c.Assert(forms, HasLen, 2)
+	c.Assert(forms[0].(*AccountStepForm).Email.Value(), Equals, "bar@example.com")
+	c.Assert(forms[1].(*ProfileStepForm).Age.Value(), Equals, int64(42))
+}
+
+func (t *WizardTest) TestSignedField(c *C) {
+	t.testSteps(c, nil)
+}
+
+func (t *WizardTest) TestStorage(c *C) {
+	t.testSteps(c, gforms.NewMemoryWizardStorage())
+}
+
+func (t *WizardTest) TestEncryptedCleanedData(c *C) {
+	var forms []gforms.Form
+	newWizard := func() *gforms.Wizard { return NewTestWizard(nil, &forms) }
+
+	w := newWizard()
+	c.Assert(w.Start(), IsNil)
+	w, _, err := submit(w, newWizard, url.Values{"Email": {" Secret@EXAMPLE.com "}})
+	c.Assert(err, IsNil)
+	c.Assert(w.Step(), Equals, 1)
+
+	b, err := base64.RawURLEncoding.DecodeString(w.StateField().Value())
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(strings.ToLower(string(b)), "secret"), Equals, false)
+	state, err := gforms.NewSigner([]byte("secret")).Decrypt(w.StateField().Value())
+	c.Assert(err, IsNil)
+	c.Assert(string(state), Matches, `wizard:.*"Email":\["Secret@example.com"\].*`)
+
+	w, _, err = submit(w, newWizard, url.Values{"wizard-back": {"1"}})
+	c.Assert(err, IsNil)
+	c.Assert(w.Step(), Equals, 0)
+	c.Assert(w.Form().(*AccountStepForm).Email.StringValue(), Equals, "Secret@example.com")
+}
+
+func (t *WizardTest) TestTamperedState(c *C) {
+	var forms []gforms.Form
+	w := NewTestWizard(nil, &forms)
+	c.Assert(w.Start(), IsNil)
+
+	_, err := NewTestWizard(nil, &forms).Process(url.Values{})
+	c.Assert(err, Equals, gforms.ErrWizardState)
+
+	state := w.StateField().Value()
+	_, err = NewTestWizard(nil, &forms).Process(url.Values{"wizard-state": {state[:len(state)-1]}})
+	c.Assert(err, Equals, gforms.ErrWizardState)
+
+	other := NewTestWizard(nil, &forms)
+	other.Prefix = "other"
+	_, err = other.Process(url.Values{"other-state": {state}})
+	c.Assert(err, Equals, gforms.ErrWizardState)
+}
+
+func (t *WizardTest) TestExpiredState(c *C) {
+	var forms []gforms.Form
+	w := NewTestWizard(nil, &forms)
+	c.Assert(w.Start(), IsNil)
+	time.Sleep(time.Millisecond)
+
+	next := NewTestWizard(nil, &forms)
+	next.MaxAge = time.Millisecond
+	_, err := next.Process(url.Values{"wizard-state": {w.StateField().Value()}})
+	c.Assert(err, Equals, gforms.ErrWizardExpired)
+}
+
+type UploadStepForm struct {
+	*gforms.BaseForm
+	Avatar *gforms.FileField
+}
+
+func (t *WizardTest) TestMultipartStep(c *C) {
+	w := gforms.NewWizard(
+		gforms.NewSigner([]byte("secret")),
+		func() gforms.Form { return &AccountStepForm{BaseForm: &gforms.BaseForm{}} },
+		func() gforms.Form { return &UploadStepForm{BaseForm: &gforms.BaseForm{}} },
+	)
+	c.Assert(w.Start(), ErrorMatches, "gforms: wizard step 1: field Avatar: file fields are not supported")
+}